@@ -0,0 +1,418 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.pennock.tech/dummyapp/internal/logging"
+	"go.pennock.tech/dummyapp/internal/stats"
+)
+
+// The log level can be changed while running, so that we can debug in
+// production without a redeploy:
+//
+//   - SIGUSR1 cycles through the levels, debug → info → warning → error.
+//   - /admin/log-level, if an admin token is configured: GET shows the level,
+//     POST with `level=NAME` sets it, and `revert=DUR` overrides the default
+//     auto-revert (`revert=0` to keep the level until changed again).  With
+//     `component=NAME` too, POST sets the level of just that component, and
+//     `level=inherit` makes it follow the base level again.
+//   - /admin/stats, with the same token: GET shows the process-wide counters
+//     from the stats package, such as panics by page and log output queues.
+//     Unlike the above, this is there even if logging is disabled.
+//
+// Any change away from the level we started with reverts after
+// -admin.log-level-revert, so that debug logging can't be left on by
+// accident.  Every change is logged.
+//
+// The admin token comes from -admin.token-file or $ADMIN_TOKEN, never from
+// a flag value, which anyone on the host could read from our command line.
+
+const (
+	envAdminToken    = "ADMIN_TOKEN"
+	adminLogLevelURL = "admin/log-level"
+	adminStatsURL    = "admin/stats"
+	// levelInherit is the level of a component following the base level.
+	levelInherit = "inherit"
+)
+
+var adminOptions struct {
+	tokenFile      string
+	logLevelRevert time.Duration
+
+	// token is read by setupAdmin.
+	token string
+}
+
+func init() {
+	flag.StringVar(&adminOptions.tokenFile, "admin.token-file", "", "file holding the bearer token for admin endpoints (default: $"+envAdminToken+"; without one, no admin endpoints)")
+	flag.DurationVar(&adminOptions.logLevelRevert, "admin.log-level-revert", 15*time.Minute, "revert runtime log level changes after this long (0 to never revert)")
+}
+
+// logLevelControl serializes level changes and owns the revert timer.
+type logLevelControl struct {
+	mu         sync.Mutex
+	logger     logging.Logger
+	baseline   logging.Level
+	generation uint64
+	revertAt   time.Time
+
+	// The same again for the components with their own levels; generations
+	// share the counter above.
+	baselineComponents   map[string]logging.Level
+	componentGenerations map[string]uint64
+	componentRevertAt    map[string]time.Time
+}
+
+var levelControl *logLevelControl
+
+// setLevel changes the level, arranging to revert to the baseline after
+// revertAfter if that's positive.  The logger is for the change message, so
+// that a request's change is logged with the request.
+func (c *logLevelControl) setLevel(logger logging.Logger, level logging.Level, revertAfter time.Duration, via string) (logging.Level, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// If we're making logging quieter, log before the change so that the
+	// message isn't itself filtered out; otherwise after, for the same reason.
+	current, _ := logging.CurrentLevel()
+	l := logger.
+		WithField("via", via).
+		WithField("previous_log_level", current.String()).
+		WithField("log_level", level.String())
+	if level != c.baseline && revertAfter > 0 {
+		l = l.WithField("revert_after", revertAfter.String())
+	}
+	if level > logging.LevelWarning {
+		l.Warning("changing log level")
+	}
+	previous, err := logging.SetLevel(level)
+	if err != nil {
+		return previous, err
+	}
+	if level <= logging.LevelWarning {
+		l.Warning("changed log level")
+	}
+
+	c.generation++
+	c.revertAt = time.Time{}
+	if level != c.baseline && revertAfter > 0 {
+		gen := c.generation
+		c.revertAt = time.Now().Add(revertAfter)
+		time.AfterFunc(revertAfter, func() { c.revert(gen) })
+	}
+	return previous, nil
+}
+
+// revert goes back to the baseline level, unless the level has been changed
+// again since the timer for this generation was started.
+func (c *logLevelControl) revert(gen uint64) {
+	c.mu.Lock()
+	stale := gen != c.generation
+	c.mu.Unlock()
+	if stale {
+		return
+	}
+	if _, err := c.setLevel(c.logger, c.baseline, 0, "revert"); err != nil {
+		c.logger.WithError(err).Error("failed to revert log level")
+	}
+}
+
+func componentLevelName(level logging.Level, ok bool) string {
+	if !ok {
+		return levelInherit
+	}
+	return level.String()
+}
+
+// setComponentLevel is setLevel for the level of one component; with
+// inherit, the component follows the base level again.
+func (c *logLevelControl) setComponentLevel(logger logging.Logger, component string, level logging.Level, inherit bool, revertAfter time.Duration, via string) (previous string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	baseline, hasBaseline := c.baselineComponents[component]
+	atBaseline := inherit == !hasBaseline && (inherit || level == baseline)
+	current, hasCurrent := logging.ComponentLevels()[component]
+	// Not "component", which would change the component of our logger.
+	l := logger.
+		WithField("via", via).
+		WithField("log_component", component).
+		WithField("previous_log_level", componentLevelName(current, hasCurrent)).
+		WithField("log_level", componentLevelName(level, !inherit))
+	if !atBaseline && revertAfter > 0 {
+		l = l.WithField("revert_after", revertAfter.String())
+	}
+	quieter := !inherit && level > logging.LevelWarning
+	if quieter {
+		l.Warning("changing component log level")
+	}
+	var hadLevel bool
+	if inherit {
+		current, hadLevel, err = logging.ClearComponentLevel(component)
+	} else {
+		current, hadLevel, err = logging.SetComponentLevel(component, level)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !quieter {
+		l.Warning("changed component log level")
+	}
+
+	c.generation++
+	gen := c.generation
+	c.componentGenerations[component] = gen
+	delete(c.componentRevertAt, component)
+	if !atBaseline && revertAfter > 0 {
+		c.componentRevertAt[component] = time.Now().Add(revertAfter)
+		time.AfterFunc(revertAfter, func() { c.revertComponent(component, gen) })
+	}
+	return componentLevelName(current, hadLevel), nil
+}
+
+// revertComponent is revert for the level of one component.
+func (c *logLevelControl) revertComponent(component string, gen uint64) {
+	c.mu.Lock()
+	stale := gen != c.componentGenerations[component]
+	baseline, ok := c.baselineComponents[component]
+	c.mu.Unlock()
+	if stale {
+		return
+	}
+	if _, err := c.setComponentLevel(c.logger, component, baseline, !ok, 0, "revert"); err != nil {
+		c.logger.WithError(err).Error("failed to revert component log level")
+	}
+}
+
+func (c *logLevelControl) status() logLevelStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	current, _ := logging.CurrentLevel()
+	status := logLevelStatus{
+		Level:    current.String(),
+		Baseline: c.baseline.String(),
+	}
+	if !c.revertAt.IsZero() {
+		revertAt := c.revertAt
+		status.RevertAt = &revertAt
+	}
+
+	levels := logging.ComponentLevels()
+	components := make(map[string]bool, len(levels)+len(c.baselineComponents))
+	for component := range levels {
+		components[component] = true
+	}
+	for component := range c.baselineComponents {
+		components[component] = true
+	}
+	if len(components) > 0 {
+		status.Components = make(map[string]componentLevelStatus, len(components))
+	}
+	for component := range components {
+		level, ok := levels[component]
+		baseline, hasBaseline := c.baselineComponents[component]
+		cs := componentLevelStatus{
+			Level:    componentLevelName(level, ok),
+			Baseline: componentLevelName(baseline, hasBaseline),
+		}
+		if revertAt, ok := c.componentRevertAt[component]; ok {
+			cs.RevertAt = &revertAt
+		}
+		status.Components[component] = cs
+	}
+	return status
+}
+
+// cycleLevel is for SIGUSR1: move to the next level, wrapping around.
+func (c *logLevelControl) cycleLevel() {
+	current, _ := logging.CurrentLevel()
+	next := logging.Levels[0]
+	for i, lvl := range logging.Levels {
+		if lvl == current && i+1 < len(logging.Levels) {
+			next = logging.Levels[i+1]
+		}
+	}
+	if _, err := c.setLevel(c.logger, next, adminOptions.logLevelRevert, "signal"); err != nil {
+		c.logger.WithError(err).Error("failed to change log level")
+	}
+}
+
+// logLevelStatus is the response; after a POST, Previous is the previous
+// level of whatever was changed, the base level or a component's.
+type logLevelStatus struct {
+	Level      string                          `json:"level"`
+	Previous   string                          `json:"previous,omitempty"`
+	Baseline   string                          `json:"baseline"`
+	RevertAt   *time.Time                      `json:"revert_at,omitempty"`
+	Components map[string]componentLevelStatus `json:"components,omitempty"`
+}
+
+type componentLevelStatus struct {
+	Level    string     `json:"level"`
+	Baseline string     `json:"baseline"`
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+}
+
+// adminAuthorized checks for the bearer token, sending the error response if
+// it's not there.
+func adminAuthorized(w http.ResponseWriter, req *http.Request) bool {
+	scheme, token, _ := strings.Cut(req.Header.Get("Authorization"), " ")
+	if strings.EqualFold(scheme, "Bearer") &&
+		subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(adminOptions.token)) == 1 {
+		return true
+	}
+	w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+	sendProblem(w, req, http.StatusUnauthorized, errorClassUnauthorized, "admin token required")
+	return false
+}
+
+func statsHandle(w http.ResponseWriter, req *http.Request) {
+	if !adminAuthorized(w, req) {
+		return
+	}
+	stats.Handler().ServeHTTP(w, req)
+}
+
+func logLevelHandle(w http.ResponseWriter, req *http.Request) {
+	if !adminAuthorized(w, req) {
+		return
+	}
+	var previous string
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPost:
+		component := req.FormValue("component")
+		inherit := component != "" && strings.EqualFold(req.FormValue("level"), levelInherit)
+		var (
+			level logging.Level
+			err   error
+		)
+		if !inherit {
+			if level, err = logging.ParseLevel(req.FormValue("level")); err != nil {
+				sendProblem(w, req, http.StatusBadRequest, errorClassBadRequest, err.Error())
+				return
+			}
+		}
+		revertAfter := adminOptions.logLevelRevert
+		if r := req.FormValue("revert"); r != "" {
+			if revertAfter, err = time.ParseDuration(r); err != nil || revertAfter < 0 {
+				sendProblem(w, req, http.StatusBadRequest, errorClassBadRequest, "revert must be a non-negative duration, such as 10m")
+				return
+			}
+		}
+		logger := loggerFromContext(req.Context())
+		if component != "" {
+			previous, err = levelControl.setComponentLevel(logger, component, level, inherit, revertAfter, "admin")
+		} else {
+			var previousLevel logging.Level
+			previousLevel, err = levelControl.setLevel(logger, level, revertAfter, "admin")
+			previous = previousLevel.String()
+		}
+		if err != nil {
+			sendProblem(w, req, http.StatusInternalServerError, errorClassInternal, err.Error())
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		sendProblem(w, req, http.StatusMethodNotAllowed, errorClassMethod, "")
+		return
+	}
+
+	status := levelControl.status()
+	status.Previous = previous
+	w.Header().Set("Content-Type", formatJSON.contentType())
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		loggerFromContext(req.Context()).WithError(err).Warning("failed writing log level status")
+	}
+}
+
+// readAdminToken returns the token from -admin.token-file if given, else
+// from the environment.
+func readAdminToken() (string, error) {
+	if adminOptions.tokenFile == "" {
+		return os.Getenv(envAdminToken), nil
+	}
+	contents, err := os.ReadFile(adminOptions.tokenFile)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(contents))
+	if token == "" {
+		return "", fmt.Errorf("admin token file %q is empty", adminOptions.tokenFile)
+	}
+	return token, nil
+}
+
+// setupAdmin should be called by the main go-routine after options have been
+// parsed and logging set up, before the webserver is set up.  The log level
+// controls are only there if logging is enabled; the counters always are.
+func setupAdmin(logger logging.Logger) error {
+	logger = logger.WithField("component", "admin")
+	if baseline, ok := logging.CurrentLevel(); ok {
+		levelControl = &logLevelControl{
+			logger:               logger,
+			baseline:             baseline,
+			baselineComponents:   logging.ComponentLevels(),
+			componentGenerations: make(map[string]uint64),
+			componentRevertAt:    make(map[string]time.Time),
+		}
+
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGUSR1)
+		go func() {
+			for range sigs {
+				levelControl.cycleLevel()
+			}
+		}()
+	}
+
+	token, err := readAdminToken()
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return nil
+	}
+	adminOptions.token = token
+	pages := []string{adminStatsURL}
+	if levelControl != nil {
+		addFirstLevelPageItem(dummyAppFirstLevelPage{
+			pageMeta: pageMeta{
+				description: "Show or change the log level",
+				category:    categoryOps,
+			},
+			name:      adminLogLevelURL,
+			function:  logLevelHandle,
+			skipIndex: true,
+		})
+		pages = append(pages, adminLogLevelURL)
+	}
+	addFirstLevelPageItem(dummyAppFirstLevelPage{
+		pageMeta: pageMeta{
+			description: "Show process-wide counters",
+			category:    categoryOps,
+		},
+		name:      adminStatsURL,
+		function:  statsHandle,
+		skipIndex: true,
+	})
+	for _, page := range pages {
+		logger.WithField("page", "/"+page).Info("admin endpoint enabled")
+	}
+	return nil
+}
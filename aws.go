@@ -117,6 +117,7 @@ func awsCollect(ctx context.Context, path string) *awsGatherItem {
 		return r
 	}
 	req = req.WithContext(ctx)
+	injectTraceContext(ctx, req.Header)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		r.err = err
@@ -159,43 +160,71 @@ func addAWSSection(w io.Writer, item *awsGatherItem) {
 }
 
 func awsHandle(w http.ResponseWriter, req *http.Request) {
-	io.WriteString(w, "<html><head><title>AWS Info Dumper</title></head><body><h1>AWS Info Dumper</h1>\n")
-
 	childCtx, cancel := context.WithTimeout(req.Context(), awsHTTPTimeout)
 	defer cancel()
 
+	// Errors which mean we have nothing to show at all are sent as whole
+	// error responses; failures of individual sections, alongside some
+	// successes, are rendered inline in the page.
+
 	if p := os.Getenv("ECS_CONTAINER_METADATA_FILE"); p != "" {
-		io.WriteString(w, "<h2>ECS metadata from file</h2>\n")
 		contents, err := os.ReadFile(p)
 		if err != nil {
-			renderErrorToHTML(w, p, err)
-		} else {
-			fmt.Fprintf(w, "\n<h3>%s</h3>\n", template.HTMLEscapeString(p))
-			template.HTMLEscape(w, contents)
+			loggerFromContext(req.Context()).WithError(err).WithField("file", p).Warning("reading ECS metadata failed")
+			sendProblem(w, req, http.StatusInternalServerError, errorClassInternal, "unable to read ECS metadata file")
+			return
 		}
+		io.WriteString(w, "<html><head><title>AWS Info Dumper</title></head><body><h1>AWS Info Dumper</h1>\n")
+		io.WriteString(w, "<h2>ECS metadata from file</h2>\n")
+		fmt.Fprintf(w, "\n<h3>%s</h3>\n", template.HTMLEscapeString(p))
+		template.HTMLEscape(w, contents)
+		return
+	}
 
-	} else {
-		io.WriteString(w, "<h2>AWS metadata service (HTTP requests)</h2>\n")
-		sections := []string{
-			"hostname",
-			"placement/availability-zone",
-			"iam/info",
-		}
-		items := doAWSGather(childCtx, sections...)
-		for _, section := range sections {
-			// may have timed out before collecting them all
-			if item, ok := items[section]; ok {
-				addAWSSection(w, item)
-			}
+	sections := []string{
+		"hostname",
+		"placement/availability-zone",
+		"iam/info",
+	}
+	items := doAWSGather(childCtx, sections...)
+	succeeded := 0
+	for _, item := range items {
+		if item.err == nil {
+			succeeded++
 		}
+	}
+	if succeeded == 0 {
+		detail := "no AWS metadata retrieved"
 		if childCtx.Err() != nil {
-			// any context expiration has _almost_ certainly been shown in the output of the
-			// addAWSSection error handling; there's a few nanoseconds race, so rather than
-			// risk aborting early without saying so, just explicitly say "hey we're done".
-			renderErrorToHTML(w, "timeout", fmt.Errorf("terminated early"))
+			detail += ", timed out"
 		}
+		sendProblem(w, req, http.StatusBadGateway, errorClassUpstream, detail)
+		return
+	}
+	if succeeded < len(sections) {
+		noteErrorClass(req, errorClassPartial)
+	}
 
+	io.WriteString(w, "<html><head><title>AWS Info Dumper</title></head><body><h1>AWS Info Dumper</h1>\n")
+	io.WriteString(w, "<h2>AWS metadata service (HTTP requests)</h2>\n")
+	for _, section := range sections {
+		// may have timed out before collecting them all
+		if item, ok := items[section]; ok {
+			addAWSSection(w, item)
+		}
+	}
+	if childCtx.Err() != nil {
+		// any context expiration has _almost_ certainly been shown in the output of the
+		// addAWSSection error handling; there's a few nanoseconds race, so rather than
+		// risk aborting early without saying so, just explicitly say "hey we're done".
+		renderErrorToHTML(w, "timeout", fmt.Errorf("terminated early"))
 	}
 }
 
-func init() { addFirstLevelPageFunc("aws", awsHandle) }
+func init() {
+	addFirstLevelPageFunc("aws", awsHandle, pageMeta{
+		description: "AWS instance or ECS task metadata",
+		category:    categoryDiagnostics,
+		icon:        "☁️",
+	})
+}
@@ -0,0 +1,216 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/felixge/httpsnoop"
+
+	"go.pennock.tech/dummyapp/internal/accesslog"
+	"go.pennock.tech/dummyapp/internal/logging"
+)
+
+// There are two ways in which we might not be mounted at `/` as far as the
+// client is concerned:
+//
+//  1. -base-path: requests arrive with the prefix still on them, and we strip
+//     it before routing; anything outside the prefix is not ours.
+//  2. X-Forwarded-Prefix: a proxy has already stripped a prefix and tells us
+//     what it was.  We only believe this from -trusted-proxies.
+//
+// Either way, everything we emit which is an absolute path (links in the
+// index, redirects) must have the full external prefix put back on.
+
+var basePathOptions struct {
+	basePath       string
+	trustedProxies prefixListFlag
+}
+
+func init() {
+	flag.StringVar(&basePathOptions.basePath, "base-path", "", "URL path prefix under which all pages are served, eg /dummy")
+	flag.Var(&basePathOptions.trustedProxies, "trusted-proxies", "comma-separated IPs or CIDR prefixes of proxies whose X-Forwarded-* headers we trust")
+}
+
+// prefixListFlag is a flag.Value for a comma-separated list of IP prefixes;
+// a bare IP is taken as a single-address prefix.  Repeated flags accumulate.
+type prefixListFlag []netip.Prefix
+
+func (l *prefixListFlag) String() string {
+	if l == nil {
+		return ""
+	}
+	parts := make([]string, len(*l))
+	for i := range *l {
+		parts[i] = (*l)[i].String()
+	}
+	return strings.Join(parts, ",")
+}
+
+func (l *prefixListFlag) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if strings.Contains(item, "/") {
+			p, err := netip.ParsePrefix(item)
+			if err != nil {
+				return err
+			}
+			*l = append(*l, p.Masked())
+			continue
+		}
+		a, err := netip.ParseAddr(item)
+		if err != nil {
+			return err
+		}
+		*l = append(*l, netip.PrefixFrom(a.Unmap(), a.Unmap().BitLen()))
+	}
+	return nil
+}
+
+func (l prefixListFlag) contains(a netip.Addr) bool {
+	a = a.Unmap()
+	for _, p := range l {
+		if p.Contains(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// fromTrustedProxy says whether the request came directly from one of the
+// -trusted-proxies, and so whether we should believe its X-Forwarded-* and
+// similar headers.
+func fromTrustedProxy(req *http.Request) bool {
+	if len(basePathOptions.trustedProxies) == 0 {
+		return false
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	a, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	return basePathOptions.trustedProxies.contains(a)
+}
+
+// cleanPathPrefix normalizes a path prefix to have a leading slash and no
+// trailing slash, so that "" and "/" both mean no prefix.
+func cleanPathPrefix(p string) string {
+	p = strings.TrimRight(strings.TrimSpace(p), "/")
+	if p != "" && !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return p
+}
+
+// basePathFromContext returns the external path prefix for the request, to
+// be put in front of any absolute path which we send to the client.
+func basePathFromContext(ctx context.Context) string {
+	p, _ := ctx.Value(dummyappBasePathKey).(string)
+	return p
+}
+
+// outsideBasePathPage is the page name for logging requests which aren't
+// under -base-path.
+const outsideBasePathPage = "outside-base-path"
+
+// basePathHandler strips -base-path from requests and records the external
+// prefix in the context, for basePathFromContext.  It also fixes up any
+// redirect to an absolute path, such as http.ServeMux adding a trailing
+// slash, so that handlers can remain oblivious.
+func basePathHandler(h http.Handler, logger logging.Logger) http.Handler {
+	base := cleanPathPrefix(basePathOptions.basePath)
+	if base != "" {
+		logger.WithField("base_path", base).Info("serving under base path")
+	}
+
+	var stripped http.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if external := basePathFromContext(req.Context()); external != "" {
+			w = prefixRedirects(w, external)
+		}
+		h.ServeHTTP(w, req)
+	})
+	// Requests outside the base path get the same wrapping as pages do, so
+	// that they're logged and given a request ID.
+	var outside http.Handler
+	if base != "" {
+		stripped = http.StripPrefix(base, stripped)
+		outside = RecoverWrapHandler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == base {
+				http.Redirect(w, req, externalPrefix(req, base)+"/", http.StatusMovedPermanently)
+				return
+			}
+			sendProblem(w, req, http.StatusNotFound, errorClassNotFound, fmt.Sprintf("not under %s", base))
+		}), outsideBasePathPage)
+		if !logger.IsDisabled() || accesslog.Enabled() {
+			outside = LogWrapHandler(outside, logger, outsideBasePathPage)
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if base != "" && !strings.HasPrefix(req.URL.Path, base+"/") {
+			outside.ServeHTTP(w, req)
+			return
+		}
+		if external := externalPrefix(req, base); external != "" {
+			req = req.WithContext(context.WithValue(req.Context(), dummyappBasePathKey, external))
+		}
+		stripped.ServeHTTP(w, req)
+	})
+}
+
+// externalPrefix is the full path prefix of the request as the client sees
+// it: the base path, after anything stripped by a trusted proxy.
+func externalPrefix(req *http.Request, base string) string {
+	if fwd := req.Header.Get("X-Forwarded-Prefix"); fwd != "" && fromTrustedProxy(req) {
+		if prefix, ok := forwardedPrefix(fwd); ok {
+			return prefix + base
+		}
+	}
+	return base
+}
+
+// forwardedPrefix cleans an X-Forwarded-Prefix value, rejecting anything
+// which would not stay a path when put at the front of a Location header or
+// link: "//host" is scheme-relative, and browsers treat `\` as `/`.  Even a
+// trusted proxy might be passing through what a client sent.
+func forwardedPrefix(fwd string) (string, bool) {
+	p := cleanPathPrefix(fwd)
+	if strings.HasPrefix(p, "//") || strings.Contains(p, `\`) {
+		return "", false
+	}
+	return p, true
+}
+
+// prefixRedirects wraps a ResponseWriter so that a Location header holding
+// an absolute path gets the external prefix added, unless already present.
+func prefixRedirects(w http.ResponseWriter, external string) http.ResponseWriter {
+	fix := func() {
+		loc := w.Header().Get("Location")
+		if strings.HasPrefix(loc, "/") && !strings.HasPrefix(loc, "//") &&
+			loc != external && !strings.HasPrefix(loc, external+"/") {
+			w.Header().Set("Location", external+loc)
+		}
+	}
+	return httpsnoop.Wrap(w, httpsnoop.Hooks{
+		WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+			return func(code int) {
+				fix()
+				next(code)
+			}
+		},
+	})
+}
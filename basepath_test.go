@@ -0,0 +1,114 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.pennock.tech/dummyapp/internal/logging"
+)
+
+func withBasePathOptions(t *testing.T, basePath, trustedProxies string) {
+	t.Helper()
+	saved := basePathOptions
+	t.Cleanup(func() { basePathOptions = saved })
+	basePathOptions.basePath = basePath
+	basePathOptions.trustedProxies = nil
+	if err := basePathOptions.trustedProxies.Set(trustedProxies); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCleanPathPrefix(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"/", ""},
+		{" /dummy/ ", "/dummy"},
+		{"dummy", "/dummy"},
+		{"/a/b//", "/a/b"},
+	} {
+		if got := cleanPathPrefix(tc.in); got != tc.want {
+			t.Errorf("cleanPathPrefix(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestExternalPrefix(t *testing.T) {
+	// httptest requests come from 192.0.2.1.
+	withBasePathOptions(t, "", "192.0.2.0/24")
+	for _, tc := range []struct {
+		name       string
+		forwarded  string
+		remoteAddr string
+		base       string
+		want       string
+	}{
+		{"none", "", "", "", ""},
+		{"base only", "", "", "/app", "/app"},
+		{"forwarded", "/proxied/", "", "", "/proxied"},
+		{"forwarded and base", "proxied", "", "/app", "/proxied/app"},
+		{"untrusted", "/proxied", "198.51.100.1:1234", "/app", "/app"},
+		{"scheme-relative", "//evil.example", "", "/app", "/app"},
+		{"scheme-relative after trim", " //evil.example/ ", "", "", ""},
+		{"backslash", `/\evil.example`, "", "", ""},
+		{"backslash inside", `/ok\path`, "", "/app", "/app"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.remoteAddr != "" {
+				req.RemoteAddr = tc.remoteAddr
+			}
+			if tc.forwarded != "" {
+				req.Header.Set("X-Forwarded-Prefix", tc.forwarded)
+			}
+			if got := externalPrefix(req, tc.base); got != tc.want {
+				t.Errorf("externalPrefix = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBasePathHandlerRedirects(t *testing.T) {
+	withBasePathOptions(t, "/app", "192.0.2.0/24")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/dir/", func(w http.ResponseWriter, req *http.Request) {})
+	h := basePathHandler(mux, logging.NilLogger())
+
+	for _, tc := range []struct {
+		name      string
+		target    string
+		forwarded string
+		code      int
+		location  string
+	}{
+		{"base path itself", "/app", "", http.StatusMovedPermanently, "/app/"},
+		{"base path forwarded", "/app", "/proxied", http.StatusMovedPermanently, "/proxied/app/"},
+		{"base path scheme-relative", "/app", "//evil.example", http.StatusMovedPermanently, "/app/"},
+		{"mux slash", "/app/dir", "", http.StatusMovedPermanently, "/app/dir/"},
+		{"mux slash forwarded", "/app/dir", "/proxied", http.StatusMovedPermanently, "/proxied/app/dir/"},
+		{"mux slash scheme-relative", "/app/dir", "//evil.example", http.StatusMovedPermanently, "/app/dir/"},
+		{"mux slash backslash", "/app/dir", `/\evil.example`, http.StatusMovedPermanently, "/app/dir/"},
+		{"outside", "/other", "", http.StatusNotFound, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			if tc.forwarded != "" {
+				req.Header.Set("X-Forwarded-Prefix", tc.forwarded)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tc.code {
+				t.Errorf("status %d, want %d", rec.Code, tc.code)
+			}
+			if got := rec.Header().Get("Location"); got != tc.location {
+				t.Errorf("Location %q, want %q", got, tc.location)
+			}
+		})
+	}
+}
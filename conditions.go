@@ -0,0 +1,86 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+var conditionOptions struct {
+	ttl time.Duration
+}
+
+func init() {
+	flag.DurationVar(&conditionOptions.ttl, "page.condition-ttl", 5*time.Second, "how long to cache the result of a conditional page's check")
+}
+
+// A pageCondition decides, per request, whether a page currently exists.
+// The same cached answer is used both for routing and for the index, so the
+// two can't disagree (beyond the moment of expiry).
+//
+// The check function is given the context of the request which caused it to
+// be evaluated, so can use loggerFromContext; it must not assume that the
+// result will only be used for that request.
+type pageCondition struct {
+	check func(ctx context.Context) bool
+
+	mu      sync.Mutex
+	value   bool
+	expires time.Time
+}
+
+// conditionally is the constructor for pageCondition, for use in the
+// onlyExistIf field of a page.
+func conditionally(check func(ctx context.Context) bool) *pageCondition {
+	return &pageCondition{check: check}
+}
+
+// exists returns the cached result of the condition, re-evaluating it if the
+// cache has expired.  A nil condition always exists.  Concurrent callers
+// hitting an expired cache wait on the one evaluation.
+func (pc *pageCondition) exists(ctx context.Context) bool {
+	if pc == nil {
+		return true
+	}
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	now := time.Now()
+	if now.Before(pc.expires) {
+		return pc.value
+	}
+	pc.value = pc.check(ctx)
+	pc.expires = now.Add(conditionOptions.ttl)
+	return pc.value
+}
+
+// conditionalHandler sends a 404 for requests when the page's condition is
+// false.
+func conditionalHandler(pc *pageCondition, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !pc.exists(req.Context()) {
+			send404(w, req)
+			return
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// conditionDirExists is a condition for a page which only exists while the
+// directory does, as for content on a volume which might be unmounted.
+func conditionDirExists(dir string) *pageCondition {
+	return conditionally(func(ctx context.Context) bool {
+		fi, err := os.Stat(dir)
+		if err != nil {
+			loggerFromContext(ctx).WithError(err).WithField("directory", dir).Debug("page directory missing")
+			return false
+		}
+		return fi.IsDir()
+	})
+}
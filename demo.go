@@ -6,8 +6,10 @@ package main
 
 import (
 	"log"
+	"log/slog"
 )
 
 func demonstrateStdlibLogger() {
 	log.Printf("this is how a module which uses stdlib logger logs")
+	slog.Info("this is how a module which uses log/slog logs", "attrs", slog.GroupValue(slog.Int("answer", 42)))
 }
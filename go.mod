@@ -1,16 +1,16 @@
 module go.pennock.tech/dummyapp
 
-go 1.19
+go 1.21
 
 require (
 	github.com/felixge/httpsnoop v1.0.3
 	github.com/rs/zerolog v1.29.0
 	github.com/sirupsen/logrus v1.9.0
 	go.pennock.tech/hmetrics v1.0.1
+	golang.org/x/sys v0.6.0
 )
 
 require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.17 // indirect
-	golang.org/x/sys v0.6.0 // indirect
 )
@@ -0,0 +1,172 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.pennock.tech/dummyapp/internal/version"
+)
+
+// indexEntry is one page as listed in the index; the field tags define the
+// JSON form of the index, which tools and smoke-tests rely upon, so treat
+// them as API.
+type indexEntry struct {
+	Name        string       `json:"name"`
+	URL         string       `json:"url"`
+	Description string       `json:"description,omitempty"`
+	Category    pageCategory `json:"category"`
+	Weight      int          `json:"weight"`
+	Icon        string       `json:"icon,omitempty"`
+}
+
+// indexGroup is the pages of one category, for rendering; the JSON form
+// keeps the flat page list, with the categories listed in display order.
+type indexGroup struct {
+	Category pageCategory
+	Pages    []indexEntry
+}
+
+type indexDocument struct {
+	Title      string         `json:"title"`
+	Program    string         `json:"program"`
+	Version    string         `json:"version"`
+	Categories []pageCategory `json:"categories"`
+	Pages      []indexEntry   `json:"pages"`
+	Groups     []indexGroup   `json:"-"`
+}
+
+var indexHTMLTemplate = template.Must(template.New("index").Parse(`<html><head><title>{{.Title}}</title></head><body><h1>{{.Title}}</h1>
+{{- range .Groups}}
+<h2>{{.Category}}</h2>
+<ul>
+{{- range .Pages}}
+ <li>{{with .Icon}}<span class="icon">{{.}}</span> {{end}}<a href="{{.URL}}">{{.Name}}</a>{{with .Description}} &mdash; {{.}}{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</body></html>
+`))
+
+func categoryRank(c pageCategory) int {
+	for i := range pageCategoryOrder {
+		if pageCategoryOrder[i] == c {
+			return i
+		}
+	}
+	return len(pageCategoryOrder) - 1
+}
+
+// indexEntries returns the pages of the site which should currently be
+// listed in the index, in display order: by category, then weight, then
+// name.  Pages without a known category are shown as categoryOther.
+func (s *site) indexEntries(ctx context.Context) []indexEntry {
+	base := basePathFromContext(ctx)
+	entries := make([]indexEntry, 0, len(s.pages))
+	for k := range s.pages {
+		if s.pages[k].skipIndex {
+			continue
+		}
+		if !s.pages[k].onlyExistIf.exists(ctx) {
+			continue
+		}
+		category := s.pages[k].category
+		if category == "" || pageCategoryOrder[categoryRank(category)] != category {
+			category = categoryOther
+		}
+		entries = append(entries, indexEntry{
+			Name:        strings.Replace(strings.TrimRight(s.pages[k].name, "/"), "/", " ", -1),
+			URL:         base + "/" + s.pages[k].name,
+			Description: s.pages[k].description,
+			Category:    category,
+			Weight:      s.pages[k].weight,
+			Icon:        s.pages[k].icon,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		ri, rj := categoryRank(entries[i].Category), categoryRank(entries[j].Category)
+		if ri != rj {
+			return ri < rj
+		}
+		if entries[i].Weight != entries[j].Weight {
+			return entries[i].Weight < entries[j].Weight
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// groupIndexEntries splits entries, already in display order, by category.
+func groupIndexEntries(entries []indexEntry) []indexGroup {
+	var groups []indexGroup
+	for _, e := range entries {
+		if len(groups) == 0 || groups[len(groups)-1].Category != e.Category {
+			groups = append(groups, indexGroup{Category: e.Category})
+		}
+		groups[len(groups)-1].Pages = append(groups[len(groups)-1].Pages, e)
+	}
+	return groups
+}
+
+func (s *site) rootHandle(w http.ResponseWriter, req *http.Request) {
+	// All paths for valid sub-trees must have been explicitly registered
+	if req.URL.Path != "/" {
+		send404(w, req)
+		return
+	}
+
+	doc := indexDocument{
+		Title:   s.title,
+		Program: version.Program,
+		Version: version.CurrentVersion(),
+		Pages:   s.indexEntries(req.Context()),
+	}
+	doc.Groups = groupIndexEntries(doc.Pages)
+	doc.Categories = make([]pageCategory, len(doc.Groups))
+	for i := range doc.Groups {
+		doc.Categories[i] = doc.Groups[i].Category
+	}
+
+	format := negotiateFormat(req, formatHTML, formatJSON, formatText)
+	w.Header().Set("Content-Type", format.contentType())
+	w.Header().Add("Vary", "Accept")
+
+	var err error
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(doc)
+	case formatText:
+		fmt.Fprintf(w, "%s %s\n", doc.Program, doc.Version)
+	textGroups:
+		for _, g := range doc.Groups {
+			fmt.Fprintf(w, "# %s\n", g.Category)
+			for _, p := range g.Pages {
+				if p.Description != "" {
+					_, err = fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, p.URL, p.Description)
+				} else {
+					_, err = fmt.Fprintf(w, "%s\t%s\n", p.Name, p.URL)
+				}
+				if err != nil {
+					break textGroups
+				}
+			}
+		}
+	default:
+		err = indexHTMLTemplate.Execute(w, doc)
+	}
+	if err != nil {
+		// Too late to change the status code, we've almost certainly started
+		// the body; all we can do is note it.
+		loggerFromContext(req.Context()).WithError(err).Warning("failed writing index")
+	}
+}
@@ -0,0 +1,360 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+// Package accesslog writes one line per request in one of the traditional
+// access log formats, for log tooling which expects those rather than our
+// structured application logs.  It writes to its own file or stream, so that
+// the two don't get mixed up.
+package accesslog
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.pennock.tech/dummyapp/internal/version"
+)
+
+// Format is an access log line format.
+type Format int
+
+// The formats we can write.
+const (
+	// FormatCommon is the Apache/NCSA Common Log Format.
+	FormatCommon Format = iota
+	// FormatCombined is Common plus the Referer and User-Agent.
+	FormatCombined
+	// FormatW3C is the W3C Extended Log File Format, with a fixed set of
+	// fields declared in the header.
+	FormatW3C
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatCommon:
+		return "common"
+	case FormatCombined:
+		return "combined"
+	case FormatW3C:
+		return "w3c"
+	}
+	return "format(" + strconv.Itoa(int(f)) + ")"
+}
+
+// Set implements flag.Value.
+func (f *Format) Set(value string) error {
+	switch strings.ToLower(value) {
+	case "common", "clf":
+		*f = FormatCommon
+	case "combined":
+		*f = FormatCombined
+	case "w3c", "extended":
+		*f = FormatW3C
+	default:
+		return fmt.Errorf("unknown access log format %q", value)
+	}
+	return nil
+}
+
+const w3cFields = "date time c-ip cs-username cs-method cs-uri-stem cs-uri-query cs-version sc-status sc-bytes time-taken cs(User-Agent) cs(Referer)"
+
+var accessOpts struct {
+	file   string
+	format Format
+}
+
+func init() {
+	flag.StringVar(&accessOpts.file, "access-log.file", "", "write an access log to this file, or - for stdout (default: no access log)")
+	flag.Var(&accessOpts.format, "access-log.format", "access log format; [common, combined, w3c]")
+}
+
+// Entry is what we know about one request, for its log line.
+type Entry struct {
+	Start      time.Time
+	RemoteAddr string // host:port, as in http.Request
+	User       string // authenticated user, if any
+	Method     string
+	RequestURI string // as sent by the client
+	Proto      string
+	Status     int
+	Bytes      int64
+	Duration   time.Duration
+	Referer    string
+	UserAgent  string
+}
+
+// A Logger writes access log lines to one sink.
+type Logger struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format Format
+	header bool // W3C header written
+}
+
+// New returns a Logger writing the given format to w.
+func New(w io.Writer, format Format) *Logger {
+	return &Logger{w: w, format: format}
+}
+
+// Log writes the line for one request.  Write errors are dropped: there's
+// nowhere better to report them, and they're not a reason to fail requests.
+func (l *Logger) Log(e *Entry) {
+	if l == nil {
+		return
+	}
+	var line []byte
+	switch l.format {
+	case FormatW3C:
+		line = appendW3C(nil, e)
+	default:
+		line = appendCommon(nil, e, l.format == FormatCombined)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.format == FormatW3C && !l.header {
+		fmt.Fprintf(l.w, "#Version: 1.0\n#Software: %s %s\n#Date: %s\n#Fields: %s\n",
+			version.Program, version.CurrentVersion(),
+			time.Now().UTC().Format("2006-01-02 15:04:05"), w3cFields)
+		l.header = true
+	}
+	_, _ = l.w.Write(line)
+}
+
+var std *Logger
+
+// ErrAlreadySetup is returned if Setup is called twice.
+var ErrAlreadySetup = errors.New("accesslog: already setup")
+
+// Setup opens the sink from the flags, if any, for Log.  It should be
+// called after flags have been parsed, before serving.
+func Setup() error {
+	if std != nil {
+		return ErrAlreadySetup
+	}
+	var w io.Writer
+	switch accessOpts.file {
+	case "":
+		return nil
+	case "-":
+		w = os.Stdout
+	default:
+		f := &reopeningFile{path: accessOpts.file}
+		if err := f.open(); err != nil {
+			return err
+		}
+		f.reopenOnSIGHUP()
+		w = f
+	}
+	std = New(w, accessOpts.format)
+	return nil
+}
+
+// reopeningFile is an io.Writer to a file which is reopened on SIGHUP, for
+// external log rotation, as the application log's -log.file is.
+type reopeningFile struct {
+	path string
+
+	mu sync.Mutex
+	f  *os.File
+}
+
+// open must be called with f.mu held, or before f is in use.
+func (f *reopeningFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	f.f = file
+	return nil
+}
+
+func (f *reopeningFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f == nil {
+		// A previous reopen failed; try again, rather than losing lines forever.
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	return f.f.Write(p)
+}
+
+// Reopen closes and reopens the file, for use after something else has
+// renamed it.
+func (f *reopeningFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f != nil {
+		f.f.Close()
+		f.f = nil
+	}
+	return f.open()
+}
+
+func (f *reopeningFile) reopenOnSIGHUP() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	go func() {
+		for range sigs {
+			if err := f.Reopen(); err != nil {
+				fmt.Fprintf(os.Stderr, "reopening access log on SIGHUP: %v\n", err)
+			}
+		}
+	}()
+}
+
+// Enabled says whether Setup configured an access log.
+func Enabled() bool { return std != nil }
+
+// Log writes to the access log configured by Setup; it does nothing if there
+// is none.
+func Log(e *Entry) { std.Log(e) }
+
+// Description returns the sink and format, for logging at startup.
+func Description() (file string, format string) {
+	return accessOpts.file, accessOpts.format.String()
+}
+
+func remoteHost(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	if addr == "" {
+		return "-"
+	}
+	return addr
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// appendEscaped appends s the way Apache does for quoted fields: quotes,
+// backslashes and anything non-printable escaped, so that a line can't be
+// forged by a client.
+func appendEscaped(b []byte, s string) []byte {
+	const hexDigits = "0123456789abcdef"
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			b = append(b, '\\', c)
+		case c < 0x20 || c >= 0x7f:
+			b = append(b, '\\', 'x', hexDigits[c>>4], hexDigits[c&0xF])
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
+
+// appendCommon formats:
+//
+//	host ident user [time] "request" status bytes
+//
+// with `"referer" "user-agent"` added for Combined.  ident is always "-".
+func appendCommon(b []byte, e *Entry, combined bool) []byte {
+	b = append(b, remoteHost(e.RemoteAddr)...)
+	b = append(b, " - "...)
+	b = appendEscaped(b, orDash(e.User))
+	b = append(b, " ["...)
+	b = e.Start.AppendFormat(b, "02/Jan/2006:15:04:05 -0700")
+	b = append(b, "] \""...)
+	b = appendEscaped(b, e.Method+" "+e.RequestURI+" "+e.Proto)
+	b = append(b, "\" "...)
+	b = strconv.AppendInt(b, int64(e.Status), 10)
+	b = append(b, ' ')
+	if e.Bytes > 0 {
+		b = strconv.AppendInt(b, e.Bytes, 10)
+	} else {
+		b = append(b, '-')
+	}
+	if combined {
+		b = append(b, " \""...)
+		b = appendEscaped(b, orDash(e.Referer))
+		b = append(b, "\" \""...)
+		b = appendEscaped(b, orDash(e.UserAgent))
+		b = append(b, '"')
+	}
+	return append(b, '\n')
+}
+
+// appendW3CToken appends a field which must not contain whitespace.
+func appendW3CToken(b []byte, s string) []byte {
+	if s == "" {
+		return append(b, '-')
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f {
+			b = append(b, '+')
+		} else {
+			b = append(b, c)
+		}
+	}
+	return b
+}
+
+// appendW3CString appends a quoted string field, with quotes doubled.
+func appendW3CString(b []byte, s string) []byte {
+	if s == "" {
+		return append(b, '-')
+	}
+	b = append(b, '"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			b = append(b, '"', '"')
+		case c < 0x20 || c == 0x7f:
+			b = append(b, ' ')
+		default:
+			b = append(b, c)
+		}
+	}
+	return append(b, '"')
+}
+
+// appendW3C formats the fields named in w3cFields, with the timestamp in UTC
+// and time-taken in seconds, per the spec.
+func appendW3C(b []byte, e *Entry) []byte {
+	stem, query, _ := strings.Cut(e.RequestURI, "?")
+	b = e.Start.UTC().AppendFormat(b, "2006-01-02 15:04:05")
+	b = append(b, ' ')
+	b = appendW3CToken(b, remoteHost(e.RemoteAddr))
+	b = append(b, ' ')
+	b = appendW3CToken(b, e.User)
+	b = append(b, ' ')
+	b = appendW3CToken(b, e.Method)
+	b = append(b, ' ')
+	b = appendW3CToken(b, stem)
+	b = append(b, ' ')
+	b = appendW3CToken(b, query)
+	b = append(b, ' ')
+	b = appendW3CToken(b, e.Proto)
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, int64(e.Status), 10)
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, e.Bytes, 10)
+	b = append(b, ' ')
+	b = strconv.AppendFloat(b, e.Duration.Seconds(), 'f', 3, 64)
+	b = append(b, ' ')
+	b = appendW3CString(b, e.UserAgent)
+	b = append(b, ' ')
+	b = appendW3CString(b, e.Referer)
+	return append(b, '\n')
+}
@@ -0,0 +1,185 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package accesslog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testEntry() *Entry {
+	return &Entry{
+		Start:      time.Date(2026, 1, 2, 15, 4, 5, 0, time.FixedZone("EST", -5*3600)),
+		RemoteAddr: "192.0.2.1:54321",
+		Method:     "GET",
+		RequestURI: "/page?q=1",
+		Proto:      "HTTP/1.1",
+		Status:     200,
+		Bytes:      1234,
+		Duration:   250 * time.Millisecond,
+		Referer:    "https://example.com/",
+		UserAgent:  "curl/8.0",
+	}
+}
+
+func TestFormats(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		modify func(*Entry)
+		common string
+		tail   string // added by combined
+		w3c    string
+	}{
+		{
+			name:   "plain",
+			common: `192.0.2.1 - - [02/Jan/2026:15:04:05 -0500] "GET /page?q=1 HTTP/1.1" 200 1234`,
+			tail:   ` "https://example.com/" "curl/8.0"`,
+			w3c:    `2026-01-02 20:04:05 192.0.2.1 - GET /page q=1 HTTP/1.1 200 1234 0.250 "curl/8.0" "https://example.com/"`,
+		},
+		{
+			name:   "user",
+			modify: func(e *Entry) { e.User = "alice" },
+			common: `192.0.2.1 - alice [02/Jan/2026:15:04:05 -0500] "GET /page?q=1 HTTP/1.1" 200 1234`,
+			tail:   ` "https://example.com/" "curl/8.0"`,
+			w3c:    `2026-01-02 20:04:05 192.0.2.1 alice GET /page q=1 HTTP/1.1 200 1234 0.250 "curl/8.0" "https://example.com/"`,
+		},
+		{
+			name: "no port, no bytes, no headers",
+			modify: func(e *Entry) {
+				e.RemoteAddr, e.Bytes, e.Referer, e.UserAgent, e.RequestURI = "192.0.2.1", 0, "", "", "/"
+			},
+			common: `192.0.2.1 - - [02/Jan/2026:15:04:05 -0500] "GET / HTTP/1.1" 200 -`,
+			tail:   ` "-" "-"`,
+			w3c:    `2026-01-02 20:04:05 192.0.2.1 - GET / - HTTP/1.1 200 0 0.250 - -`,
+		},
+		{
+			name:   "no remote address",
+			modify: func(e *Entry) { e.RemoteAddr = "" },
+			common: `- - - [02/Jan/2026:15:04:05 -0500] "GET /page?q=1 HTTP/1.1" 200 1234`,
+			tail:   ` "https://example.com/" "curl/8.0"`,
+			w3c:    `2026-01-02 20:04:05 - - GET /page q=1 HTTP/1.1 200 1234 0.250 "curl/8.0" "https://example.com/"`,
+		},
+		{
+			name: "forgery attempt",
+			modify: func(e *Entry) {
+				e.RequestURI = "/a\"b\\c\n1.2.3.4 - - [x] \"GET / HTTP/1.1\" 200 1?q=é"
+				e.UserAgent = "ua \"quoted\" \\ \r\ntab\t"
+				e.Referer = "ref\x00\x7f\"end"
+				e.User = "bob smith"
+			},
+			common: `192.0.2.1 - bob smith [02/Jan/2026:15:04:05 -0500] "GET /a\"b\\c\x0a1.2.3.4 - - [x] \"GET / HTTP/1.1\" 200 1?q=\xc3\xa9 HTTP/1.1" 200 1234`,
+			tail:   ` "ref\x00\x7f\"end" "ua \"quoted\" \\ \x0d\x0atab\x09"`,
+			w3c:    `2026-01-02 20:04:05 192.0.2.1 bob+smith GET /a"b\c+1.2.3.4+-+-+[x]+"GET+/+HTTP/1.1"+200+1 q=++ HTTP/1.1 200 1234 0.250 "ua ""quoted"" \   tab " "ref  ""end"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := testEntry()
+			if tc.modify != nil {
+				tc.modify(e)
+			}
+			common := string(appendCommon(nil, e, false))
+			if want := tc.common + "\n"; common != want {
+				t.Errorf("common:\n got %s\nwant %s", common, want)
+			}
+			combined := string(appendCommon(nil, e, true))
+			if wantCombined := tc.common + tc.tail + "\n"; combined != wantCombined {
+				t.Errorf("combined:\n got %s\nwant %s", combined, wantCombined)
+			}
+			if w3c := string(appendW3C(nil, e)); w3c != tc.w3c+"\n" {
+				t.Errorf("w3c:\n got %s\nwant %s", w3c, tc.w3c+"\n")
+			}
+			for name, line := range map[string]string{"common": common, "combined": combined, "w3c": string(appendW3C(nil, e))} {
+				if strings.Count(line, "\n") != 1 || strings.ContainsAny(line[:len(line)-1], "\r\x00\x7f") {
+					t.Errorf("%s line not a single clean line: %q", name, line)
+				}
+			}
+		})
+	}
+}
+
+func TestAppendEscaped(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"plain text", "plain text"},
+		{`say "hi"`, `say \"hi\"`},
+		{`back\slash`, `back\\slash`},
+		{"line\nbreak\r", `line\x0abreak\x0d`},
+		{"nul\x00del\x7f", `nul\x00del\x7f`},
+		{"café", `caf\xc3\xa9`},
+	} {
+		if got := string(appendEscaped(nil, tc.in)); got != tc.want {
+			t.Errorf("appendEscaped(%q) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestW3CHeaderOnce(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, FormatW3C)
+	l.Log(testEntry())
+	l.Log(testEntry())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("want 4 header lines and 2 entries, have:\n%s", buf.String())
+	}
+	for i, prefix := range []string{"#Version: 1.0", "#Software: ", "#Date: ", "#Fields: " + w3cFields} {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("header line %d = %q, want prefix %q", i, lines[i], prefix)
+		}
+	}
+	for _, line := range lines[4:] {
+		if strings.HasPrefix(line, "#") {
+			t.Errorf("header repeated: %q", line)
+		}
+	}
+
+	buf.Reset()
+	New(&buf, FormatCombined).Log(testEntry())
+	if strings.Contains(buf.String(), "#") {
+		t.Errorf("combined format has a header: %q", buf.String())
+	}
+}
+
+func TestNilLogger(t *testing.T) {
+	var l *Logger
+	l.Log(testEntry())
+}
+
+func TestReopeningFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	f := &reopeningFile{path: path}
+	if err := f.open(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { f.f.Close() }()
+	l := New(f, FormatCommon)
+
+	l.Log(testEntry())
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	l.Log(testEntry())
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	l.Log(testEntry())
+
+	for name, want := range map[string]int{path + ".1": 2, path: 1} {
+		contents, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Count(string(contents), "\n"); got != want {
+			t.Errorf("%s has %d lines, want %d", filepath.Base(name), got, want)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package logging
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// The record sinks write to the network, and we don't want a slow or absent
+// collector to add latency to requests.  So each sink gets a bounded queue
+// and its own goroutine, and logging only enqueues.  When the queue is full,
+// -log.async.policy says whether to block the caller until there's space, or
+// to drop the record and count it.
+//
+// When a sink fails with what looks like a network problem, the goroutine
+// retries the same record with backoff, so records queue up while the remote
+// is away, and are delivered in order once it's back, preceded by a count of
+// any which were dropped.  Other failures, such as a record too large to send
+// or which can't be encoded, won't go away on retrying, so that record is
+// dropped at once; and after asyncMaxAttempts, so is any record, so that one
+// can't hold up everything behind it forever.
+//
+// Counters are exposed through AsyncStats, for the stats package to publish,
+// and Shutdown flushes the queues.
+
+const (
+	asyncPolicyBlock = "block"
+	asyncPolicyDrop  = "drop"
+
+	// asyncMaxAttempts is enough to ride out a restart of the remote, with
+	// the backoff below, about a minute.
+	asyncMaxAttempts = 10
+
+	// asyncExitFlushTimeout bounds the flush when dying on a fatal error.
+	asyncExitFlushTimeout = 2 * time.Second
+)
+
+// Variables so that tests needn't wait.
+var (
+	asyncMinBackoff = 100 * time.Millisecond
+	asyncMaxBackoff = 30 * time.Second
+)
+
+var asyncOpts struct {
+	queue  int
+	policy string
+}
+
+func init() {
+	flag.IntVar(&asyncOpts.queue, "log.async.queue", 1000, "records to queue for each remote log output")
+	flag.StringVar(&asyncOpts.policy, "log.async.policy", asyncPolicyDrop, "when a remote log output queue is full; [block, drop]")
+}
+
+// AsyncOutputStats are the counters for one remote log output.
+type AsyncOutputStats struct {
+	QueueDepth    int    `json:"queue_depth"`
+	QueueCapacity int    `json:"queue_capacity"`
+	Sent          uint64 `json:"sent"`
+	Dropped       uint64 `json:"dropped"`
+	Failures      uint64 `json:"failures"`
+}
+
+type asyncRecord struct {
+	level   string
+	message string
+	fields  map[string]interface{}
+
+	// flushed, if set, marks a flush request rather than a record, and is
+	// closed when everything queued before it has been dealt with.
+	flushed chan struct{}
+}
+
+type asyncSink struct {
+	name  string
+	sink  recordSink
+	queue chan asyncRecord
+	block bool
+
+	// abandon is closed once a flush has run out of time, so that we stop
+	// retrying and drain what's left as dropped.
+	abandon     chan struct{}
+	abandonOnce sync.Once
+
+	sent     atomic.Uint64
+	dropped  atomic.Uint64
+	failures atomic.Uint64
+	// unreported counts drops not yet reported to the sink itself.
+	unreported atomic.Uint64
+}
+
+var asyncOutputs struct {
+	sync.Mutex
+	sinks []*asyncSink
+}
+
+// newAsyncSink starts the goroutine for a sink and registers it for stats
+// and Shutdown.
+func newAsyncSink(name string, sink recordSink) (*asyncSink, error) {
+	a := &asyncSink{
+		name:    name,
+		sink:    sink,
+		queue:   make(chan asyncRecord, max(asyncOpts.queue, 1)),
+		abandon: make(chan struct{}),
+	}
+	switch strings.ToLower(asyncOpts.policy) {
+	case asyncPolicyBlock:
+		a.block = true
+	case asyncPolicyDrop:
+	default:
+		return nil, fmt.Errorf("unknown log queue policy %q", asyncOpts.policy)
+	}
+	asyncOutputs.Lock()
+	asyncOutputs.sinks = append(asyncOutputs.sinks, a)
+	asyncOutputs.Unlock()
+	go a.run()
+	return a, nil
+}
+
+// sendRecord makes asyncSink a recordSink; it queues the record and so never
+// returns an error.
+func (a *asyncSink) sendRecord(level, message string, fields map[string]interface{}) error {
+	rec := asyncRecord{level: level, message: message, fields: fields}
+	if a.block {
+		a.queue <- rec
+		return nil
+	}
+	select {
+	case a.queue <- rec:
+	default:
+		a.drop()
+	}
+	return nil
+}
+
+func (a *asyncSink) drop() {
+	a.dropped.Add(1)
+	a.unreported.Add(1)
+}
+
+func (a *asyncSink) run() {
+	for rec := range a.queue {
+		if rec.flushed != nil {
+			close(rec.flushed)
+			continue
+		}
+		if !a.deliver(rec.level, rec.message, rec.fields) {
+			a.drop()
+			continue
+		}
+		a.sent.Add(1)
+		if n := a.unreported.Swap(0); n > 0 {
+			if !a.deliver("warning", "dropped log records which could not be delivered",
+				map[string]interface{}{"dropped": n, "output": a.name}) {
+				a.unreported.Add(n)
+			}
+		}
+	}
+}
+
+// deliver retries with backoff until the sink takes the record, the error
+// isn't worth retrying, we've tried asyncMaxAttempts times, or we've been
+// told to abandon it.
+func (a *asyncSink) deliver(level, message string, fields map[string]interface{}) bool {
+	backoff := asyncMinBackoff
+	for attempt := 1; ; attempt++ {
+		err := a.sink.sendRecord(level, message, fields)
+		if err == nil {
+			return true
+		}
+		a.failures.Add(1)
+		if attempt >= asyncMaxAttempts || !retryable(err) {
+			return false
+		}
+		select {
+		case <-a.abandon:
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, asyncMaxBackoff)
+	}
+}
+
+// retryable says whether a send error looks like a connection or temporary
+// problem, which might go away, rather than something wrong with the record.
+// A system call error decides, since network errors wrap those too, such as
+// for a datagram too large to send, which resending won't help.
+func retryable(err error) bool {
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		switch errno {
+		case syscall.ECONNREFUSED, syscall.ECONNRESET, syscall.ECONNABORTED, syscall.EPIPE,
+			syscall.ENOBUFS, syscall.ENOENT, syscall.ENETUNREACH, syscall.EHOSTUNREACH:
+			return true
+		}
+		return errno.Temporary()
+	}
+	var ne net.Error
+	return errors.As(err, &ne)
+}
+
+// flush waits until everything queued so far has been dealt with.  If ctx
+// expires first, the sink gives up on the rest.
+func (a *asyncSink) flush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case a.queue <- asyncRecord{flushed: done}:
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+		}
+	case <-ctx.Done():
+	}
+	a.abandonOnce.Do(func() { close(a.abandon) })
+	return fmt.Errorf("flushing %s log output: %w", a.name, ctx.Err())
+}
+
+func (a *asyncSink) stats() AsyncOutputStats {
+	return AsyncOutputStats{
+		QueueDepth:    len(a.queue),
+		QueueCapacity: cap(a.queue),
+		Sent:          a.sent.Load(),
+		Dropped:       a.dropped.Load(),
+		Failures:      a.failures.Load(),
+	}
+}
+
+// AsyncStats returns the counters for each remote log output, keyed by the
+// name of the output.
+func AsyncStats() map[string]AsyncOutputStats {
+	asyncOutputs.Lock()
+	defer asyncOutputs.Unlock()
+	m := make(map[string]AsyncOutputStats, len(asyncOutputs.sinks))
+	for _, a := range asyncOutputs.sinks {
+		m[a.name] = a.stats()
+	}
+	return m
+}
+
+// Shutdown flushes the queues of the remote log outputs, in parallel,
+// returning once they're empty or ctx is done.  Logging still works
+// afterwards, but anything logged after Shutdown might not be delivered.
+func Shutdown(ctx context.Context) error {
+	asyncOutputs.Lock()
+	sinks := append([]*asyncSink(nil), asyncOutputs.sinks...)
+	asyncOutputs.Unlock()
+
+	errs := make([]error, len(sinks))
+	var wg sync.WaitGroup
+	for i := range sinks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = sinks[i].flush(ctx)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushBeforeExit is for the fatal paths, which bypass any deferred Shutdown.
+func flushBeforeExit() {
+	ctx, cancel := context.WithTimeout(context.Background(), asyncExitFlushTimeout)
+	defer cancel()
+	_ = Shutdown(ctx)
+}
@@ -0,0 +1,163 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"reflect"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeSink records what it's sent, failing with whatever fail returns for
+// the message and the attempt at it, counting from 1.
+type fakeSink struct {
+	fail func(message string, attempt int) error
+
+	mu       sync.Mutex
+	attempts map[string]int
+	sent     []string
+}
+
+func (f *fakeSink) sendRecord(level, message string, fields map[string]interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.attempts == nil {
+		f.attempts = make(map[string]int)
+	}
+	f.attempts[message]++
+	if err := f.fail(message, f.attempts[message]); err != nil {
+		return err
+	}
+	f.sent = append(f.sent, message)
+	return nil
+}
+
+func withFastBackoff(t *testing.T) {
+	t.Helper()
+	savedMin, savedMax := asyncMinBackoff, asyncMaxBackoff
+	t.Cleanup(func() { asyncMinBackoff, asyncMaxBackoff = savedMin, savedMax })
+	asyncMinBackoff, asyncMaxBackoff = time.Millisecond, time.Millisecond
+}
+
+// sendAndFlush queues the messages through a new asyncSink for f, and waits
+// for them to be dealt with.
+func sendAndFlush(t *testing.T, f *fakeSink, messages ...string) *asyncSink {
+	t.Helper()
+	a, err := newAsyncSink("test", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range messages {
+		if err := a.sendRecord("info", m, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := a.flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+const droppedMessage = "dropped log records which could not be delivered"
+
+func connRefused() error {
+	return &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+}
+
+func TestAsyncDropsUnsendableRecord(t *testing.T) {
+	withFastBackoff(t)
+	f := &fakeSink{fail: func(message string, _ int) error {
+		if message == "poison" {
+			return errors.New("GELF message too large to chunk")
+		}
+		return nil
+	}}
+	a := sendAndFlush(t, f, "first", "poison", "second", "third")
+
+	if want := []string{"first", "second", droppedMessage, "third"}; !reflect.DeepEqual(f.sent, want) {
+		t.Errorf("sent %q, want %q", f.sent, want)
+	}
+	if f.attempts["poison"] != 1 {
+		t.Errorf("unsendable record tried %d times", f.attempts["poison"])
+	}
+	if s := a.stats(); s.Sent != 3 || s.Dropped != 1 || s.Failures != 1 {
+		t.Errorf("stats %+v", s)
+	}
+}
+
+func TestAsyncRetriesNetworkErrors(t *testing.T) {
+	withFastBackoff(t)
+	f := &fakeSink{fail: func(message string, attempt int) error {
+		if message == "first" && attempt <= 3 {
+			return connRefused()
+		}
+		return nil
+	}}
+	a := sendAndFlush(t, f, "first", "second")
+
+	if want := []string{"first", "second"}; !reflect.DeepEqual(f.sent, want) {
+		t.Errorf("sent %q, want %q", f.sent, want)
+	}
+	if s := a.stats(); s.Sent != 2 || s.Dropped != 0 || s.Failures != 3 {
+		t.Errorf("stats %+v", s)
+	}
+}
+
+func TestAsyncGivesUpAfterMaxAttempts(t *testing.T) {
+	withFastBackoff(t)
+	f := &fakeSink{fail: func(message string, _ int) error {
+		if message == "stuck" {
+			return connRefused()
+		}
+		return nil
+	}}
+	a := sendAndFlush(t, f, "stuck", "next")
+
+	if want := []string{"next", droppedMessage}; !reflect.DeepEqual(f.sent, want) {
+		t.Errorf("sent %q, want %q", f.sent, want)
+	}
+	if f.attempts["stuck"] != asyncMaxAttempts {
+		t.Errorf("tried %d times, want %d", f.attempts["stuck"], asyncMaxAttempts)
+	}
+	if s := a.stats(); s.Sent != 1 || s.Dropped != 1 || s.Failures != asyncMaxAttempts {
+		t.Errorf("stats %+v", s)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	_, jsonErr := json.Marshal(math.NaN())
+	for _, tc := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection refused", connRefused(), true},
+		{"reset", &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.ECONNRESET)}, true},
+		{"timeout", &net.OpError{Op: "write", Net: "tcp", Err: os.ErrDeadlineExceeded}, true},
+		{"dns", &net.DNSError{Err: "no such host", Name: "logs.invalid"}, true},
+		{"bare errno", syscall.ECONNREFUSED, true},
+		{"wrapped errno", fmt.Errorf("sendmsg: %w", syscall.EAGAIN), true},
+		{"datagram too large", &net.OpError{Op: "write", Net: "udp", Err: os.NewSyscallError("write", syscall.EMSGSIZE)}, false},
+		{"invalid argument", syscall.EINVAL, false},
+		{"journald socket gone", &net.OpError{Op: "dial", Net: "unixgram", Err: os.NewSyscallError("connect", syscall.ENOENT)}, true},
+		{"encoding", jsonErr, false},
+		{"too large to chunk", errors.New("GELF message too large to chunk"), false},
+	} {
+		if got := retryable(tc.err); got != tc.want {
+			t.Errorf("%s: retryable(%v) = %v, want %v", tc.name, tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package logging
+
+import (
+	"fmt"
+	"time"
+)
+
+// Loggers are derived for parts of the program with a "component" field, and
+// -log.level can give those their own levels, such as
+// "info,stats=debug,aws=warn".  The backend is set to the most verbose level
+// in use, and componentLogger filters by component.
+//
+// Logging which bypasses our Logger, such as the stdlib log diversion or
+// zerolog.Ctx(), is only filtered by the backend, so may come through at
+// the most verbose level of any component.
+
+const componentField = "component"
+
+// componentLogger tracks the component field, dropping messages below the
+// level for that component, or the base level if it has none.
+type componentLogger struct {
+	Logger
+	component string
+}
+
+func withComponentLevels(l Logger) Logger {
+	if l.IsDisabled() {
+		return l
+	}
+	return componentLogger{Logger: l}
+}
+
+// WithField adds a k/v pair, noting the component if that's what it is.
+func (c componentLogger) WithField(key string, value interface{}) Logger {
+	component := c.component
+	if key == componentField {
+		component = fmt.Sprint(value)
+	}
+	return componentLogger{c.Logger.WithField(key, value), component}
+}
+
+// WithError adds an error, keeping the component.
+func (c componentLogger) WithError(err error) Logger {
+	return componentLogger{c.Logger.WithError(err), c.component}
+}
+
+// Str adds a string field, noting the component if that's what it is.
+func (c componentLogger) Str(key, value string) Logger {
+	component := c.component
+	if key == componentField {
+		component = value
+	}
+	return componentLogger{c.Logger.Str(key, value), component}
+}
+
+// Int adds an integer field, noting the component if that's what it is.
+func (c componentLogger) Int(key string, value int64) Logger {
+	return c.withTyped(c.Logger.Int(key, value), Int(key, value))
+}
+
+// Dur adds a duration field, noting the component if that's what it is.
+func (c componentLogger) Dur(key string, value time.Duration) Logger {
+	return c.withTyped(c.Logger.Dur(key, value), Dur(key, value))
+}
+
+// Bool adds a boolean field, noting the component if that's what it is.
+func (c componentLogger) Bool(key string, value bool) Logger {
+	return c.withTyped(c.Logger.Bool(key, value), Bool(key, value))
+}
+
+// Err is WithError.
+func (c componentLogger) Err(err error) Logger { return c.WithError(err) }
+
+// Fields adds several fields, noting the component if one of them is it.
+func (c componentLogger) Fields(fields ...Field) Logger {
+	component := c.component
+	for _, f := range fields {
+		if f.Key == componentField {
+			component = f.text()
+		}
+	}
+	return componentLogger{c.Logger.Fields(fields...), component}
+}
+
+// unlimited is for Unlimited, keeping the component.
+func (c componentLogger) unlimited() Logger {
+	return componentLogger{Unlimited(c.Logger), c.component}
+}
+
+func (c componentLogger) withTyped(l Logger, f Field) Logger {
+	component := c.component
+	if f.Key == componentField {
+		component = f.text()
+	}
+	return componentLogger{l, component}
+}
+
+func (c componentLogger) enabled(level Level) bool {
+	t := componentLevels.Load()
+	if t == nil || len(t.components) == 0 {
+		// The backend is at the base level and does the filtering.
+		return true
+	}
+	if l, ok := t.components[c.component]; ok && c.component != "" {
+		return level >= l
+	}
+	return level >= t.base
+}
+
+func (c componentLogger) Debug(message string) {
+	if c.enabled(LevelDebug) {
+		c.Logger.Debug(message)
+	}
+}
+
+func (c componentLogger) Info(message string) {
+	if c.enabled(LevelInfo) {
+		c.Logger.Info(message)
+	}
+}
+
+func (c componentLogger) Warning(message string) {
+	if c.enabled(LevelWarning) {
+		c.Logger.Warning(message)
+	}
+}
+
+func (c componentLogger) Error(message string) {
+	if c.enabled(LevelError) {
+		c.Logger.Error(message)
+	}
+}
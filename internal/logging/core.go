@@ -5,8 +5,10 @@
 package logging
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 )
 
 // Logger is the interface API which the rest of our code should use for logging.
@@ -17,6 +19,17 @@ type Logger interface {
 	WithField(key string, value interface{}) Logger
 	WithError(err error) Logger
 
+	// Typed variants of WithField, which implementations should make cheaper
+	// where they can, and Fields to add several at once; Err is WithError.
+	// Dur renders as a floating-point count of microseconds in every
+	// implementation, so name the key to suit, such as "wait_us".
+	Str(key, value string) Logger
+	Int(key string, value int64) Logger
+	Dur(key string, value time.Duration) Logger
+	Bool(key string, value bool) Logger
+	Err(err error) Logger
+	Fields(fields ...Field) Logger
+
 	// while logrus uses `args ...interface{}`, we push hard enough for
 	// structured logging that not only do we not use any of the Foof()
 	// variants, but we also only ever call these with one item, a string
@@ -30,16 +43,30 @@ type Logger interface {
 	// IsDisabled is a simple one-liner which real implementations should use
 	// to return false, unless they support the concept of disabling.
 	IsDisabled() bool
+
+	// AttachToContext returns a context which also carries the
+	// implementation's native logger, if the implementation has its own
+	// facility for that, so that libraries using that logging library
+	// directly pick up our fields.  Implementations without such a facility
+	// should use a one-liner returning ctx unchanged.  This does not replace
+	// the caller keeping our Logger in the context for our own code.
+	AttachToContext(ctx context.Context) context.Context
 }
 
 // Setup is used to setup logging.
 func Setup() Logger {
 	if Enabled() {
-		l := implSetup()
+		l := withComponentLevels(withRateLimit(withRedaction(implSetup())))
+		setSlogDefault(l)
+		levelState.Lock()
+		levelState.adjustable = !l.IsDisabled()
+		levelState.Unlock()
 		l.WithField("implementation", implPackage).Info("logging library")
 		return l
 	}
-	return newNilLoggerDisablingLog()
+	l := newNilLoggerDisablingLog()
+	setSlogDefault(l)
+	return l
 }
 
 // NilLogger returns a typed nil which satisfies Logger but does nothing.
@@ -65,6 +92,24 @@ func (n *nilLogger) WithField(key string, value interface{}) Logger { return n }
 // WithError on *nilLogger just returns the *nilLogger.
 func (n *nilLogger) WithError(err error) Logger { return n }
 
+// Str on *nilLogger just returns the *nilLogger.
+func (n *nilLogger) Str(key, value string) Logger { return n }
+
+// Int on *nilLogger just returns the *nilLogger.
+func (n *nilLogger) Int(key string, value int64) Logger { return n }
+
+// Dur on *nilLogger just returns the *nilLogger.
+func (n *nilLogger) Dur(key string, value time.Duration) Logger { return n }
+
+// Bool on *nilLogger just returns the *nilLogger.
+func (n *nilLogger) Bool(key string, value bool) Logger { return n }
+
+// Err on *nilLogger just returns the *nilLogger.
+func (n *nilLogger) Err(err error) Logger { return n }
+
+// Fields on *nilLogger just returns the *nilLogger.
+func (n *nilLogger) Fields(fields ...Field) Logger { return n }
+
 // Debug on *nilLogger does nothing.
 func (n *nilLogger) Debug(message string) {}
 
@@ -79,3 +124,6 @@ func (n *nilLogger) Error(message string) {}
 
 // IsDisabled on *nilLogger always returns true
 func (n *nilLogger) IsDisabled() bool { return true }
+
+// AttachToContext on *nilLogger returns the context unchanged.
+func (n *nilLogger) AttachToContext(ctx context.Context) context.Context { return ctx }
@@ -0,0 +1,99 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package logging
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// WithField takes an interface{}, which costs an allocation to box the value
+// and, for zerolog, a trip through encoding/json.  The typed methods on
+// Logger avoid that, and Fields adds several at once, so that zerolog copies
+// its context once rather than per field.  These Field constructors share
+// their names with those methods:
+//
+//	l.Fields(logging.Str("request", id), logging.Int("code", code))
+
+type fieldKind uint8
+
+const (
+	fieldAny fieldKind = iota
+	fieldString
+	fieldInt
+	fieldDuration
+	fieldBool
+)
+
+// Field is one key/value pair for Logger.Fields.
+type Field struct {
+	Key  string
+	kind fieldKind
+	str  string
+	num  int64
+	any  interface{}
+}
+
+// Str is a string field.
+func Str(key, value string) Field {
+	return Field{Key: key, kind: fieldString, str: value}
+}
+
+// Int is an integer field.
+func Int(key string, value int64) Field {
+	return Field{Key: key, kind: fieldInt, num: value}
+}
+
+// Dur is a duration field; see Logger.Dur for how it's rendered.
+func Dur(key string, value time.Duration) Field {
+	return Field{Key: key, kind: fieldDuration, num: int64(value)}
+}
+
+// Bool is a boolean field.
+func Bool(key string, value bool) Field {
+	f := Field{Key: key, kind: fieldBool}
+	if value {
+		f.num = 1
+	}
+	return f
+}
+
+// Any is a field of any type, as WithField takes.
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, kind: fieldAny, any: value}
+}
+
+// value boxes the value, for implementations without typed fields.
+func (f Field) value() interface{} {
+	switch f.kind {
+	case fieldString:
+		return f.str
+	case fieldInt:
+		return f.num
+	case fieldDuration:
+		return durationMicros(time.Duration(f.num))
+	case fieldBool:
+		return f.num != 0
+	}
+	return f.any
+}
+
+// text renders the value as a string, for when it's used as a component.
+func (f Field) text() string {
+	switch f.kind {
+	case fieldString:
+		return f.str
+	case fieldInt:
+		return strconv.FormatInt(f.num, 10)
+	}
+	return fmt.Sprint(f.value())
+}
+
+// durationMicros is how we render durations, as zerolog is configured to:
+// a floating-point count of microseconds, matching our "_us" fields.
+func durationMicros(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
@@ -0,0 +1,118 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package logging
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// These compare the ways of adding the eight or so fields which a request
+// log line carries, both on the bare backend and through the wrappers which
+// Setup adds (component levels, rate limiting and redaction), since those
+// re-wrap the logger on every call adding a field, so each field call costs
+// an allocation per wrapper; prefer one Fields call over a chain.  Run with
+// each build tag to compare backends:
+//
+//	go test -run - -bench RequestFields ./internal/logging/
+//	go test -run - -bench RequestFields -tags zerolog ./internal/logging/
+
+var benchLoggers struct {
+	sync.Once
+	backend Logger
+	setup   Logger
+}
+
+func benchmarkLoggers(b *testing.B) []struct {
+	name   string
+	logger Logger
+} {
+	benchLoggers.Do(func() {
+		// Log JSON, as in production, to /dev/null, with no limits so that
+		// it's never rotated.
+		logOpts.json = true
+		fileOpts.path = os.DevNull
+		fileOpts.maxSizeMB, fileOpts.maxAge = 0, 0
+		// A burst we'll never reach, so that everything is still logged but
+		// goes through the rate limiter.
+		rateLimitOpts.burst = 1 << 62
+		benchLoggers.setup = Setup()
+		benchLoggers.backend = implSetup()
+	})
+	if benchLoggers.setup.IsDisabled() {
+		b.Skip("logging disabled")
+	}
+	return []struct {
+		name   string
+		logger Logger
+	}{
+		{"backend", benchLoggers.backend},
+		{"setup", benchLoggers.setup},
+	}
+}
+
+const benchDuration = 1234567 * time.Nanosecond
+
+func BenchmarkRequestFieldsWithField(b *testing.B) {
+	for _, bl := range benchmarkLoggers(b) {
+		b.Run(bl.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bl.logger.
+					WithField("request", "01M58Y46QB814W4B7ZVETY63T8").
+					WithField("page", "aws").
+					WithField("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736").
+					WithField("span_id", "00f067aa0ba902b7").
+					WithField("method", "GET").
+					WithField("code", 200).
+					WithField("duration", benchDuration).
+					WithField("sampled", false).
+					Info("responded")
+			}
+		})
+	}
+}
+
+func BenchmarkRequestFieldsTyped(b *testing.B) {
+	for _, bl := range benchmarkLoggers(b) {
+		b.Run(bl.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bl.logger.
+					Str("request", "01M58Y46QB814W4B7ZVETY63T8").
+					Str("page", "aws").
+					Str("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736").
+					Str("span_id", "00f067aa0ba902b7").
+					Str("method", "GET").
+					Int("code", 200).
+					Dur("duration", benchDuration).
+					Bool("sampled", false).
+					Info("responded")
+			}
+		})
+	}
+}
+
+func BenchmarkRequestFieldsBatch(b *testing.B) {
+	for _, bl := range benchmarkLoggers(b) {
+		b.Run(bl.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bl.logger.Fields(
+					Str("request", "01M58Y46QB814W4B7ZVETY63T8"),
+					Str("page", "aws"),
+					Str("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"),
+					Str("span_id", "00f067aa0ba902b7"),
+					Str("method", "GET"),
+					Int("code", 200),
+					Dur("duration", benchDuration),
+					Bool("sampled", false),
+				).Info("responded")
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package logging
+
+import (
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// With -log.file, the backends write there instead of to stderr; the file is
+// rotated by size and/or age, with the rotated files compressed and pruned,
+// and is reopened on SIGHUP for when something external does the rotation.
+// -log.no-local inhibits the file too, since it's replacing stdio.
+//
+// Rotated files are named for the time of rotation, as FILE.20060102T150405Z
+// with .gz added once compressed, so that they sort in order.
+
+const rotatedTimeLayout = "20060102T150405Z"
+
+var fileOpts struct {
+	path      string
+	maxSizeMB int
+	maxAge    time.Duration
+	keep      int
+	compress  bool
+}
+
+func init() {
+	flag.StringVar(&fileOpts.path, "log.file", "", "write logs to this file instead of stderr")
+	flag.IntVar(&fileOpts.maxSizeMB, "log.file.max-size", 100, "rotate the log file when it would exceed this many MiB (0 for no limit)")
+	flag.DurationVar(&fileOpts.maxAge, "log.file.max-age", 0, "rotate the log file after it's been open this long (0 for no limit)")
+	flag.IntVar(&fileOpts.keep, "log.file.keep", 7, "how many rotated log files to keep (0 to keep all)")
+	flag.BoolVar(&fileOpts.compress, "log.file.compress", true, "gzip rotated log files")
+}
+
+// localOutput returns where the backend should write what would otherwise go
+// to stderr, and whether that's our log file rather than stderr (so no colors).
+func localOutput() (w io.Writer, isFile bool, err error) {
+	if fileOpts.path == "" {
+		return os.Stderr, false, nil
+	}
+	rf := &rotatingFile{
+		path:     fileOpts.path,
+		maxSize:  int64(fileOpts.maxSizeMB) << 20,
+		maxAge:   fileOpts.maxAge,
+		keep:     fileOpts.keep,
+		compress: fileOpts.compress,
+	}
+	if err := rf.open(); err != nil {
+		return nil, true, err
+	}
+	rf.reopenOnSIGHUP()
+	return rf, true, nil
+}
+
+// rotatingFile is an io.Writer to a log file which rotates itself.  Each
+// Write goes entirely to one file, so log lines aren't split across files.
+type rotatingFile struct {
+	path     string
+	maxSize  int64
+	maxAge   time.Duration
+	keep     int
+	compress bool
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	opened time.Time
+
+	// housekeeping serializes compression and pruning, which run in the
+	// background so that rotation doesn't stall logging.
+	housekeeping sync.Mutex
+}
+
+func (rf *rotatingFile) open() error {
+	f, err := os.OpenFile(rf.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	rf.f, rf.size, rf.opened = f, fi.Size(), time.Now()
+	return nil
+}
+
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.f == nil {
+		// A previous reopen failed; try again, rather than losing logs forever.
+		if err := rf.open(); err != nil {
+			return 0, err
+		}
+	}
+	if rf.size > 0 && (rf.maxSize > 0 && rf.size+int64(len(p)) > rf.maxSize ||
+		rf.maxAge > 0 && time.Since(rf.opened) >= rf.maxAge) {
+		if err := rf.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "log file rotation failed: %v\n", err)
+		}
+	}
+	if rf.f == nil {
+		return 0, os.ErrClosed
+	}
+	n, err := rf.f.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// rotate must be called with rf.mu held.
+func (rf *rotatingFile) rotate() error {
+	if err := rf.f.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "closing log file: %v\n", err)
+	}
+	rf.f = nil
+	now := time.Now().UTC()
+	rotated := rf.path + "." + now.Format(rotatedTimeLayout)
+	for i := 1; fileExists(rotated) || fileExists(rotated+".gz"); i++ {
+		rotated = fmt.Sprintf("%s.%s-%d", rf.path, now.Format(rotatedTimeLayout), i)
+	}
+	renameErr := os.Rename(rf.path, rotated)
+	if err := rf.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+	go rf.tidy(rotated)
+	return nil
+}
+
+// Reopen closes and reopens the log file, for use after something else has
+// renamed it.
+func (rf *rotatingFile) Reopen() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.f != nil {
+		rf.f.Close()
+		rf.f = nil
+	}
+	return rf.open()
+}
+
+func (rf *rotatingFile) reopenOnSIGHUP() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	go func() {
+		for range sigs {
+			if err := rf.Reopen(); err != nil {
+				fmt.Fprintf(os.Stderr, "reopening log file on SIGHUP: %v\n", err)
+			}
+		}
+	}()
+}
+
+// tidy compresses a just-rotated file and prunes old ones.  Errors go to
+// stderr, since the logger is what's being tidied.
+func (rf *rotatingFile) tidy(rotated string) {
+	rf.housekeeping.Lock()
+	defer rf.housekeeping.Unlock()
+	if rf.compress {
+		if err := gzipFile(rotated); err != nil {
+			fmt.Fprintf(os.Stderr, "compressing rotated log file: %v\n", err)
+		}
+	}
+	if rf.keep > 0 {
+		if err := rf.prune(); err != nil {
+			fmt.Fprintf(os.Stderr, "pruning rotated log files: %v\n", err)
+		}
+	}
+}
+
+func (rf *rotatingFile) prune() error {
+	matches, err := filepath.Glob(rf.path + ".*")
+	if err != nil {
+		return err
+	}
+	type rotatedFile struct {
+		name  string
+		stamp string
+		seq   int
+	}
+	var rotated []rotatedFile
+	prefix := rf.path + "."
+	for _, m := range matches {
+		if strings.HasSuffix(m, ".tmp") {
+			continue
+		}
+		suffix := strings.TrimSuffix(strings.TrimPrefix(m, prefix), ".gz")
+		if len(suffix) < len(rotatedTimeLayout) {
+			continue
+		}
+		stamp, rest := suffix[:len(rotatedTimeLayout)], suffix[len(rotatedTimeLayout):]
+		if _, err := time.Parse(rotatedTimeLayout, stamp); err != nil {
+			continue
+		}
+		// Files rotated within the same second as another get "-N" added.
+		seq := 0
+		if rest != "" {
+			n, err := strconv.Atoi(strings.TrimPrefix(rest, "-"))
+			if err != nil || rest[0] != '-' || n < 1 {
+				continue
+			}
+			seq = n
+		}
+		rotated = append(rotated, rotatedFile{name: m, stamp: stamp, seq: seq})
+	}
+	if len(rotated) <= rf.keep {
+		return nil
+	}
+	// Not sorting by name, as "-N" would sort before ".gz".
+	sort.Slice(rotated, func(i, j int) bool {
+		if rotated[i].stamp != rotated[j].stamp {
+			return rotated[i].stamp < rotated[j].stamp
+		}
+		return rotated[i].seq < rotated[j].seq
+	})
+	for _, r := range rotated[:len(rotated)-rf.keep] {
+		if err := os.Remove(r.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func gzipFile(name string) error {
+	in, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp := name + ".gz.tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	_, err = io.Copy(zw, in)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, name+".gz")
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(name)
+}
+
+func fileExists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
@@ -0,0 +1,231 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package logging
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRotatingFile(t *testing.T, maxSize int64, maxAge time.Duration) *rotatingFile {
+	t.Helper()
+	rf := &rotatingFile{
+		path:    filepath.Join(t.TempDir(), "test.log"),
+		maxSize: maxSize,
+		maxAge:  maxAge,
+	}
+	if err := rf.open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rf.f.Close() })
+	return rf
+}
+
+func rotatedFiles(t *testing.T, rf *rotatingFile) []string {
+	t.Helper()
+	matches, err := filepath.Glob(rf.path + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+func writeString(t *testing.T, w io.Writer, s string) {
+	t.Helper()
+	if n, err := io.WriteString(w, s); err != nil || n != len(s) {
+		t.Fatalf("writing %d bytes: wrote %d, %v", len(s), n, err)
+	}
+}
+
+func fileSize(t *testing.T, name string) int64 {
+	t.Helper()
+	fi, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fi.Size()
+}
+
+func TestRotatingFileSizeThreshold(t *testing.T) {
+	rf := newTestRotatingFile(t, 100, 0)
+
+	writeString(t, rf, strings.Repeat("a", 60))
+	writeString(t, rf, strings.Repeat("b", 40))
+	if got := rotatedFiles(t, rf); len(got) != 0 {
+		t.Fatalf("rotated at exactly the limit: %v", got)
+	}
+
+	writeString(t, rf, "c")
+	got := rotatedFiles(t, rf)
+	if len(got) != 1 {
+		t.Fatalf("want 1 rotated file once over the limit, have %v", got)
+	}
+	if size := fileSize(t, got[0]); size != 100 {
+		t.Errorf("rotated file has %d bytes, want 100", size)
+	}
+	if size := fileSize(t, rf.path); size != 1 {
+		t.Errorf("new file has %d bytes, want 1", size)
+	}
+
+	// A write bigger than the limit goes whole into a fresh file.
+	writeString(t, rf, strings.Repeat("d", 250))
+	if got := rotatedFiles(t, rf); len(got) != 2 {
+		t.Fatalf("want 2 rotated files, have %v", got)
+	}
+	if size := fileSize(t, rf.path); size != 250 {
+		t.Errorf("new file has %d bytes, want 250", size)
+	}
+	writeString(t, rf, "e")
+	if got := rotatedFiles(t, rf); len(got) != 3 {
+		t.Fatalf("want 3 rotated files, have %v", got)
+	}
+	for i, name := range rotatedFiles(t, rf) {
+		if i > 0 && !strings.HasPrefix(name, rf.path+"."+time.Now().UTC().Format("20060102")) {
+			t.Errorf("rotated file %q not named for the time", name)
+		}
+	}
+}
+
+func TestRotatingFileEmptyNeverRotates(t *testing.T) {
+	rf := newTestRotatingFile(t, 10, time.Nanosecond)
+	rf.opened = time.Now().Add(-time.Hour)
+	writeString(t, rf, strings.Repeat("x", 50))
+	if got := rotatedFiles(t, rf); len(got) != 0 {
+		t.Errorf("empty file rotated: %v", got)
+	}
+}
+
+func TestRotatingFileNoSizeLimit(t *testing.T) {
+	rf := newTestRotatingFile(t, 0, 0)
+	for i := 0; i < 100; i++ {
+		writeString(t, rf, strings.Repeat("x", 1000))
+	}
+	if got := rotatedFiles(t, rf); len(got) != 0 {
+		t.Errorf("rotated without limits: %v", got)
+	}
+}
+
+func TestRotatingFileExistingSizeCounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, bytes.Repeat([]byte("x"), 90), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rf := &rotatingFile{path: path, maxSize: 100}
+	if err := rf.open(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { rf.f.Close() }()
+	writeString(t, rf, strings.Repeat("y", 20))
+	if got := rotatedFiles(t, rf); len(got) != 1 {
+		t.Fatalf("want 1 rotated file, have %v", got)
+	}
+}
+
+func TestRotatingFileAgeThreshold(t *testing.T) {
+	rf := newTestRotatingFile(t, 0, time.Hour)
+	writeString(t, rf, "first\n")
+	writeString(t, rf, "second\n")
+	if got := rotatedFiles(t, rf); len(got) != 0 {
+		t.Fatalf("rotated before max age: %v", got)
+	}
+
+	rf.opened = time.Now().Add(-time.Hour)
+	writeString(t, rf, "third\n")
+	got := rotatedFiles(t, rf)
+	if len(got) != 1 {
+		t.Fatalf("want 1 rotated file after max age, have %v", got)
+	}
+	if contents, _ := os.ReadFile(got[0]); string(contents) != "first\nsecond\n" {
+		t.Errorf("rotated file holds %q", contents)
+	}
+	if time.Since(rf.opened) > time.Minute {
+		t.Errorf("age not reset by rotation")
+	}
+}
+
+func TestRotatingFilePrune(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+	rotated := []string{
+		path + ".20260101T000000Z.gz",
+		path + ".20260102T000000Z.gz",
+		path + ".20260102T000000Z-1.gz",
+		path + ".20260103T000000Z",
+	}
+	unrelated := []string{
+		path,
+		path + ".20260104T000000Z.gz.tmp",
+		path + ".old",
+		path + ".bak.20260101T000000Z",
+		path + ".20260101T000000Z.orig",
+	}
+	for _, name := range append(append([]string(nil), rotated...), unrelated...) {
+		if err := os.WriteFile(name, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rf := &rotatingFile{path: path, keep: 2}
+	if err := rf.prune(); err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range rotated {
+		if want := i >= len(rotated)-2; fileExists(name) != want {
+			t.Errorf("after pruning, %s exists = %v, want %v", filepath.Base(name), !want, want)
+		}
+	}
+	for _, name := range unrelated {
+		if !fileExists(name) {
+			t.Errorf("pruning removed %s", filepath.Base(name))
+		}
+	}
+}
+
+func TestRotatingFileTidyCompresses(t *testing.T) {
+	rf := newTestRotatingFile(t, 10, 0)
+	rf.compress = true
+	writeString(t, rf, "0123456789")
+	rf.mu.Lock()
+	err := rf.rotate()
+	rf.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// rotate started tidy in the background; wait for it.
+	rf.housekeeping.Lock()
+	defer rf.housekeeping.Unlock()
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		if got := rotatedFiles(t, rf); len(got) == 1 && strings.HasSuffix(got[0], ".gz") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("rotated file not compressed: %v", rotatedFiles(t, rf))
+		}
+		rf.housekeeping.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		rf.housekeeping.Lock()
+	}
+
+	f, err := os.Open(rotatedFiles(t, rf)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contents, err := io.ReadAll(zr); err != nil || string(contents) != "0123456789" {
+		t.Errorf("compressed file holds %q, %v", contents, err)
+	}
+}
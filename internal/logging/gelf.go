@@ -0,0 +1,226 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package logging
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.pennock.tech/dummyapp/internal/version"
+)
+
+// With -log.gelf.address, each log event is also sent to Graylog as a GELF
+// 1.1 message, <https://go2docs.graylog.org/current/getting_in_log_data/gelf.html>,
+// with our fields as additional fields and the version.LogPairs() as static
+// fields on every message.  Over UDP, messages are gzipped and chunked if
+// too large for one datagram; over TCP they're null-delimited, uncompressed,
+// as Graylog requires.
+
+const (
+	gelfChunkMagic0 = 0x1e
+	gelfChunkMagic1 = 0x0f
+	gelfChunkHeader = 12 // magic, message ID, sequence number and count
+	gelfMaxChunks   = 128
+)
+
+var gelfOpts struct {
+	address   string
+	proto     string
+	chunkSize int
+	compress  bool
+}
+
+func init() {
+	flag.StringVar(&gelfOpts.address, "log.gelf.address", "", "host:port to send logs to as GELF")
+	flag.StringVar(&gelfOpts.proto, "log.gelf.proto", "udp", "protocol for GELF; [udp, tcp]")
+	flag.IntVar(&gelfOpts.chunkSize, "log.gelf.chunk-size", 1420, "maximum UDP datagram size for GELF, chunking larger messages")
+	flag.BoolVar(&gelfOpts.compress, "log.gelf.compress", true, "gzip GELF messages sent over UDP")
+}
+
+type gelfSink struct {
+	proto     string
+	address   string
+	chunkSize int
+	compress  bool
+	host      string
+	static    map[string]interface{}
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newGELFSink() (*gelfSink, error) {
+	proto := strings.ToLower(gelfOpts.proto)
+	switch proto {
+	case "udp", "tcp":
+	default:
+		return nil, fmt.Errorf("unknown GELF protocol %q", gelfOpts.proto)
+	}
+	if proto == "udp" && gelfOpts.chunkSize <= gelfChunkHeader {
+		return nil, fmt.Errorf("GELF chunk size %d too small", gelfOpts.chunkSize)
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = version.Program
+	}
+	g := &gelfSink{
+		proto:     proto,
+		address:   gelfOpts.address,
+		chunkSize: gelfOpts.chunkSize,
+		compress:  gelfOpts.compress,
+		host:      host,
+		static:    make(map[string]interface{}),
+	}
+	for _, pair := range version.LogPairs() {
+		g.static[gelfFieldName(pair.Key)] = pair.Value
+	}
+	if err := g.dial(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *gelfSink) dial() error {
+	conn, err := net.DialTimeout(g.proto, g.address, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	g.conn = conn
+	return nil
+}
+
+// gelfFieldName maps a field key to a GELF additional field name, which must
+// match ^[\w\.\-]*$ and be prefixed with an underscore; _id is reserved.
+func gelfFieldName(key string) string {
+	var b strings.Builder
+	b.WriteByte('_')
+	for _, r := range key {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '_' || r == '.' || r == '-' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	if b.String() == "_id" {
+		return "_field_id"
+	}
+	return b.String()
+}
+
+// gelfValue renders a field value as GELF allows, a string or a number.
+func gelfValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case string, json.Number,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return t
+	}
+	return journalValue(v)
+}
+
+func (g *gelfSink) sendRecord(level, message string, fields map[string]interface{}) error {
+	msg := make(map[string]interface{}, len(fields)+len(g.static)+5)
+	for k, v := range g.static {
+		msg[k] = v
+	}
+	for k, v := range fields {
+		msg[gelfFieldName(k)] = gelfValue(v)
+	}
+	if message == "" {
+		// short_message is mandatory and must not be empty.
+		message = "-"
+	}
+	msg["version"] = "1.1"
+	msg["host"] = g.host
+	msg["short_message"] = message
+	msg["timestamp"] = float64(time.Now().UnixMicro()) / 1e6
+	msg["level"] = syslogPriority(level)
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.proto == "tcp" {
+		return g.sendTCP(payload)
+	}
+	return g.sendUDP(payload)
+}
+
+// sendTCP writes the null-delimited message, redialling once if the
+// connection has gone away.
+func (g *gelfSink) sendTCP(payload []byte) error {
+	payload = append(payload, 0)
+	for attempt := 0; ; attempt++ {
+		if g.conn == nil {
+			if err := g.dial(); err != nil {
+				return err
+			}
+		}
+		_, err := g.conn.Write(payload)
+		if err == nil {
+			return nil
+		}
+		g.conn.Close()
+		g.conn = nil
+		if attempt > 0 {
+			return err
+		}
+	}
+}
+
+func (g *gelfSink) sendUDP(payload []byte) error {
+	if g.compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(payload); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		payload = buf.Bytes()
+	}
+	if len(payload) <= g.chunkSize {
+		_, err := g.conn.Write(payload)
+		return err
+	}
+
+	dataSize := g.chunkSize - gelfChunkHeader
+	count := (len(payload) + dataSize - 1) / dataSize
+	if count > gelfMaxChunks {
+		return errors.New("GELF message too large to chunk")
+	}
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return err
+	}
+	chunk := make([]byte, 0, g.chunkSize)
+	for seq := 0; seq < count; seq++ {
+		end := (seq + 1) * dataSize
+		if end > len(payload) {
+			end = len(payload)
+		}
+		chunk = append(chunk[:0], gelfChunkMagic0, gelfChunkMagic1)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(seq), byte(count))
+		chunk = append(chunk, payload[seq*dataSize:end]...)
+		if _, err := g.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package logging
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// With -log.journald, we send to systemd-journald using its native protocol,
+// <https://systemd.io/JOURNAL_NATIVE_PROTOCOL/>, so that our structured
+// fields become journal fields (REQUEST, PAGE, CODE, ...) instead of being
+// flattened into a syslog line, and the level becomes the PRIORITY.
+//
+// This is a recordSink, so works with every backend.  The socket handling is
+// Linux-only.
+
+const defaultJournalSocket = "/run/systemd/journal/socket"
+
+var journaldOpts struct {
+	enabled bool
+	socket  string
+}
+
+func init() {
+	flag.BoolVar(&journaldOpts.enabled, "log.journald", false, "log to systemd-journald, with fields as journal fields")
+	flag.StringVar(&journaldOpts.socket, "log.journald.socket", defaultJournalSocket, "journald native protocol socket")
+}
+
+type journalField struct {
+	name  string
+	value string
+}
+
+// journalFieldName maps our field keys onto what journald allows: upper-case
+// letters, digits and underscores, not starting with an underscore or digit.
+// Keys which would collide with the fields we set ourselves get a prefix.
+func journalFieldName(key string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(key) {
+		if 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	name := strings.TrimLeft(b.String(), "_")
+	switch {
+	case name == "":
+		return "FIELD_EMPTY"
+	case name[0] >= '0' && name[0] <= '9',
+		name == "MESSAGE", name == "PRIORITY", name == "SYSLOG_IDENTIFIER":
+		return "FIELD_" + name
+	}
+	return name
+}
+
+// journalValue renders a field value as text: strings and errors as
+// themselves, anything else as JSON if we can.
+func journalValue(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case error:
+		return t.Error()
+	case fmt.Stringer:
+		return t.String()
+	case json.Number:
+		return t.String()
+	}
+	if b, err := json.Marshal(v); err == nil {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// journalFields converts a set of fields, sorted for stable output.
+func journalFields(data map[string]interface{}) []journalField {
+	fields := make([]journalField, 0, len(data))
+	for k, v := range data {
+		fields = append(fields, journalField{journalFieldName(k), journalValue(v)})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+	return fields
+}
+
+// appendJournalEntry serializes one entry in the native protocol; values
+// with newlines use the length-prefixed form.
+func appendJournalEntry(b []byte, priority int, identifier, message string, fields []journalField) []byte {
+	appendField := func(name, value string) {
+		b = append(b, name...)
+		if strings.IndexByte(value, '\n') < 0 {
+			b = append(b, '=')
+			b = append(b, value...)
+		} else {
+			b = append(b, '\n')
+			b = binary.LittleEndian.AppendUint64(b, uint64(len(value)))
+			b = append(b, value...)
+		}
+		b = append(b, '\n')
+	}
+	appendField("MESSAGE", message)
+	appendField("PRIORITY", fmt.Sprint(priority))
+	appendField("SYSLOG_IDENTIFIER", identifier)
+	for _, f := range fields {
+		appendField(f.name, f.value)
+	}
+	return b
+}
+
+// sendRecord makes journalSink a recordSink.
+func (j *journalSink) sendRecord(level, message string, fields map[string]interface{}) error {
+	return j.send(syslogPriority(level), message, journalFields(fields))
+}
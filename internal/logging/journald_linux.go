@@ -0,0 +1,80 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package logging
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// journalSink sends entries to journald over its datagram socket.
+type journalSink struct {
+	identifier string
+	conn       *net.UnixConn
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func newJournalSink(socket, identifier string) (*journalSink, error) {
+	// Dialing fails now if journald isn't there, rather than on first log.
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &journalSink{identifier: identifier, conn: conn}, nil
+}
+
+func (j *journalSink) send(priority int, message string, fields []journalField) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.buf = appendJournalEntry(j.buf[:0], priority, j.identifier, message, fields)
+	_, err := j.conn.Write(j.buf)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		return err
+	}
+	return j.sendViaMemfd(j.buf)
+}
+
+// sendViaMemfd is the protocol's way of sending an entry too large for a
+// datagram: write it to a sealed memfd and send the descriptor.
+func (j *journalSink) sendViaMemfd(entry []byte) error {
+	fd, err := unix.MemfdCreate("journal-entry", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+	for b := entry; len(b) > 0; {
+		n, err := unix.Write(fd, b)
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	if _, err := unix.FcntlInt(uintptr(fd), unix.F_ADD_SEALS, unix.F_SEAL_SHRINK|unix.F_SEAL_GROW|unix.F_SEAL_WRITE|unix.F_SEAL_SEAL); err != nil {
+		return err
+	}
+	// net refuses WriteMsgUnix on a connected datagram socket, so go direct.
+	raw, err := j.conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var sendErr error
+	err = raw.Write(func(sock uintptr) bool {
+		sendErr = unix.Sendmsg(int(sock), nil, unix.UnixRights(fd), nil, 0)
+		return !errors.Is(sendErr, unix.EAGAIN)
+	})
+	if err != nil {
+		return err
+	}
+	return sendErr
+}
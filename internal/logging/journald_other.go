@@ -0,0 +1,20 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+//go:build !linux
+// +build !linux
+
+package logging
+
+import "errors"
+
+type journalSink struct{}
+
+func newJournalSink(socket, identifier string) (*journalSink, error) {
+	return nil, errors.New("journald logging is only supported on Linux")
+}
+
+func (j *journalSink) send(priority int, message string, fields []journalField) error {
+	return nil
+}
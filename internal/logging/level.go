@@ -0,0 +1,207 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Level is a logging level which can be changed at runtime, common across
+// the backends.  The backends accept more levels at startup via -log.level,
+// but these are the ones which make sense to switch between while running;
+// anything more verbose reports as LevelDebug and anything more severe as
+// LevelError.
+type Level int
+
+// The levels, in order of increasing severity.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarning
+	LevelError
+)
+
+// Levels is all the Levels, in order.
+var Levels = []Level{LevelDebug, LevelInfo, LevelWarning, LevelError}
+
+// ErrLevelFixed is returned when trying to change the level of a disabled
+// logger, since there's nothing to change.
+var ErrLevelFixed = errors.New("logging: disabled, so level can't be changed")
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarning:
+		return "warning"
+	case LevelError:
+		return "error"
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// ParseLevel parses a level name, accepting the same aliases as -log.level.
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarning, nil
+	case "error", "err":
+		return LevelError, nil
+	}
+	return 0, fmt.Errorf("logging: unknown level %q", name)
+}
+
+// parseLevelSpec splits a -log.level value, such as "info,stats=debug,aws=warn",
+// into the base level, left for the backend to parse since it knows more
+// levels than we do, and the levels for components.  The base defaults to
+// "info" if only components are given.
+func parseLevelSpec(spec string) (base string, components map[string]Level, err error) {
+	base = "info"
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		component, name, found := strings.Cut(item, "=")
+		if !found {
+			base = item
+			continue
+		}
+		component = strings.TrimSpace(component)
+		if component == "" {
+			return "", nil, fmt.Errorf("logging: missing component in %q", item)
+		}
+		level, err := ParseLevel(name)
+		if err != nil {
+			return "", nil, err
+		}
+		if components == nil {
+			components = make(map[string]Level)
+		}
+		components[component] = level
+	}
+	return base, components, nil
+}
+
+// levelState is the base level and the per-component levels.  The backend
+// runs at the most verbose of them all, and componentLogger filters by
+// component; so that it needn't take the lock, each change publishes a new
+// componentLevelTable.
+var levelState struct {
+	sync.Mutex
+	adjustable bool
+	base       Level
+	components map[string]Level // replaced, never modified
+}
+
+type componentLevelTable struct {
+	base       Level
+	components map[string]Level
+}
+
+var componentLevels atomic.Pointer[componentLevelTable]
+
+// setComponentLevels is called by implSetup once the backend has its base
+// level.
+func setComponentLevels(components map[string]Level) {
+	levelState.Lock()
+	defer levelState.Unlock()
+	levelState.base = implLevel()
+	levelState.components = components
+	applyLevelsLocked()
+}
+
+func applyLevelsLocked() {
+	lowest := levelState.base
+	for _, level := range levelState.components {
+		lowest = min(lowest, level)
+	}
+	// Only when it's a real change, so as not to lose a more verbose level
+	// than we know of, such as trace, which we report as debug.
+	if lowest != implLevel() {
+		implSetLevel(lowest)
+	}
+	componentLevels.Store(&componentLevelTable{base: levelState.base, components: levelState.components})
+}
+
+// CurrentLevel returns the active base level, with ok false if logging is
+// disabled.
+func CurrentLevel() (level Level, ok bool) {
+	levelState.Lock()
+	defer levelState.Unlock()
+	if !levelState.adjustable {
+		return 0, false
+	}
+	return levelState.base, true
+}
+
+// SetLevel changes the active base level immediately, for all Loggers
+// derived from the one returned by Setup which don't have a level for their
+// component, returning the level which was active before.
+func SetLevel(level Level) (previous Level, err error) {
+	levelState.Lock()
+	defer levelState.Unlock()
+	if !levelState.adjustable {
+		return 0, ErrLevelFixed
+	}
+	previous = levelState.base
+	levelState.base = level
+	applyLevelsLocked()
+	return previous, nil
+}
+
+// ComponentLevels returns the components which have their own level.
+func ComponentLevels() map[string]Level {
+	levelState.Lock()
+	defer levelState.Unlock()
+	m := make(map[string]Level, len(levelState.components))
+	for component, level := range levelState.components {
+		m[component] = level
+	}
+	return m
+}
+
+// SetComponentLevel gives a component its own level, returning the level it
+// had before, with hadLevel false if it was following the base level.
+func SetComponentLevel(component string, level Level) (previous Level, hadLevel bool, err error) {
+	return changeComponentLevel(component, &level)
+}
+
+// ClearComponentLevel makes a component follow the base level again,
+// returning the level it had before, as SetComponentLevel does.
+func ClearComponentLevel(component string) (previous Level, hadLevel bool, err error) {
+	return changeComponentLevel(component, nil)
+}
+
+func changeComponentLevel(component string, level *Level) (previous Level, hadLevel bool, err error) {
+	levelState.Lock()
+	defer levelState.Unlock()
+	if !levelState.adjustable {
+		return 0, false, ErrLevelFixed
+	}
+	previous, hadLevel = levelState.components[component]
+	components := make(map[string]Level, len(levelState.components)+1)
+	for c, l := range levelState.components {
+		components[c] = l
+	}
+	if level != nil {
+		components[component] = *level
+	} else {
+		delete(components, component)
+	}
+	levelState.components = components
+	applyLevelsLocked()
+	return previous, hadLevel, nil
+}
@@ -2,41 +2,34 @@
 // All rights reserved, except as granted under license.
 // Licensed per file LICENSE.txt
 
-//go:build logrus || !zerolog
-// +build logrus !zerolog
+//go:build logrus || (!zerolog && !slog)
+// +build logrus !zerolog,!slog
 
 package logging
 
 import (
+	"context"
 	"flag"
 	stdlog "log"
-	"log/syslog"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
-	logrus_syslog "github.com/sirupsen/logrus/hooks/syslog"
 
 	"go.pennock.tech/dummyapp/internal/version"
 )
 
 var logOpts struct {
-	level        string
-	json         bool
-	syslogRemote string
-	syslogProto  string
-	syslogTag    string
-	noLocal      bool
+	level     string
+	json      bool
+	syslogTag string
+	noLocal   bool
 }
 
 func init() {
-	flag.StringVar(&logOpts.level, "log.level", "info", "logging level")
+	flag.StringVar(&logOpts.level, "log.level", "info", "logging level, optionally with levels for components, such as info,stats=debug")
 	flag.BoolVar(&logOpts.json, "log.json", false, "format logs into JSON")
 	flag.BoolVar(&logOpts.noLocal, "log.no-local", false, "inhibit stdio logging, only use any log hooks (syslog)")
-	flag.StringVar(&logOpts.syslogRemote, "log.syslog.address", "", "host:port to send logs to via syslog")
-	// We can add more variants, such as "rfcFOO", if needed:
-	flag.StringVar(&logOpts.syslogProto, "log.syslog.proto", "udp", "protocol to use; [udp, tcp]")
 	flag.StringVar(&logOpts.syslogTag, "log.syslog.tag", version.Program, "tag for syslog messages")
 }
 
@@ -44,12 +37,41 @@ func init() {
 // It has to be usable after flags init but _before_ implSetup, being called by
 // Setup() in core.go to decide if we should be called.
 func Enabled() bool {
-	return !logOpts.noLocal || logOpts.syslogRemote != ""
+	return !logOpts.noLocal || recordSinksConfigured()
 }
 
 // Used by Setup() to log which we are:
 const implPackage = "logrus"
 
+// rootLogger is kept for runtime level changes.
+var rootLogger *logrus.Logger
+
+func implLevel() Level {
+	switch lvl := rootLogger.GetLevel(); {
+	case lvl >= logrus.DebugLevel:
+		return LevelDebug
+	case lvl == logrus.InfoLevel:
+		return LevelInfo
+	case lvl == logrus.WarnLevel:
+		return LevelWarning
+	default:
+		return LevelError
+	}
+}
+
+func implSetLevel(level Level) {
+	switch level {
+	case LevelDebug:
+		rootLogger.SetLevel(logrus.DebugLevel)
+	case LevelInfo:
+		rootLogger.SetLevel(logrus.InfoLevel)
+	case LevelWarning:
+		rootLogger.SetLevel(logrus.WarnLevel)
+	default:
+		rootLogger.SetLevel(logrus.ErrorLevel)
+	}
+}
+
 // -------------------------8< wrap logrus type >8-------------------------
 
 type wrapLogrus struct {
@@ -66,6 +88,38 @@ func (w wrapLogrus) WithError(err error) Logger {
 	return wrapLogrus{w.Entry.WithError(err)}
 }
 
+// Str adds a string field; logrus has no typed fields, so this is WithField.
+func (w wrapLogrus) Str(key, value string) Logger {
+	return wrapLogrus{w.Entry.WithField(key, value)}
+}
+
+// Int adds an integer field.
+func (w wrapLogrus) Int(key string, value int64) Logger {
+	return wrapLogrus{w.Entry.WithField(key, value)}
+}
+
+// Dur adds a duration field, as microseconds.
+func (w wrapLogrus) Dur(key string, value time.Duration) Logger {
+	return wrapLogrus{w.Entry.WithField(key, durationMicros(value))}
+}
+
+// Bool adds a boolean field.
+func (w wrapLogrus) Bool(key string, value bool) Logger {
+	return wrapLogrus{w.Entry.WithField(key, value)}
+}
+
+// Err is WithError.
+func (w wrapLogrus) Err(err error) Logger { return w.WithError(err) }
+
+// Fields adds several fields at once, copying the entry's fields only once.
+func (w wrapLogrus) Fields(fields ...Field) Logger {
+	data := make(logrus.Fields, len(fields))
+	for _, f := range fields {
+		data[f.Key] = f.value()
+	}
+	return wrapLogrus{w.Entry.WithFields(data)}
+}
+
 // Debug logs at debug level.
 // It maps our reduced signature to the fuller signature of logrus.
 func (w wrapLogrus) Debug(message string) { w.Entry.Debug(message) }
@@ -85,8 +139,29 @@ func (w wrapLogrus) Error(message string) { w.Entry.Error(message) }
 // IsDisabled is always false for a logrus logger.
 func (w wrapLogrus) IsDisabled() bool { return false }
 
+// AttachToContext returns the context unchanged: logrus has no facility for
+// retrieving a logger from a context.
+func (w wrapLogrus) AttachToContext(ctx context.Context) context.Context { return ctx }
+
 // -------------------------8< wrap logrus type >8-------------------------
 
+// recordHook sends each entry to the record sinks, with its fields intact.
+type recordHook struct {
+	sinks []recordSink
+}
+
+func (h recordHook) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (h recordHook) Fire(e *logrus.Entry) error {
+	var firstErr error
+	for _, s := range h.sinks {
+		if err := s.sendRecord(e.Level.String(), e.Message, e.Data); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
 // implSetup sets up logging and is called by Setup (usually)
 //
 // Setup should be changed to add whatever remote logging you want;
@@ -111,12 +186,22 @@ func (w wrapLogrus) IsDisabled() bool { return false }
 // fast loop and chew system resources.
 func implSetup() Logger {
 	l := logrus.New()
-	lvl, err := logrus.ParseLevel(logOpts.level)
+	base, components, err := parseLevelSpec(logOpts.level)
+	if err != nil {
+		time.Sleep(time.Second)
+		l.WithError(err).Fatal("unable to parse logging level")
+	}
+	lvl, err := logrus.ParseLevel(base)
 	if err != nil {
 		time.Sleep(time.Second)
 		l.WithError(err).Fatal("unable to parse logging level")
 	}
 	l.SetLevel(lvl)
+	rootLogger = l
+	setComponentLevels(components)
+	// Fatal exits through here, so we don't lose what's queued for the
+	// record sinks.
+	logrus.RegisterExitHandler(flushBeforeExit)
 
 	// other plugins available include "logstash", in case that's of interest
 	// in your environment.
@@ -124,28 +209,12 @@ func implSetup() Logger {
 		l.Formatter = &logrus.JSONFormatter{}
 	}
 
-	// nb: looks like logrus_syslog as a hook is not filtering out ANSI color
-	// escape sequences.  So probably best to just use with JSON.  Or tell me
-	// what I'm doing wrong with logging setup.
-	if logOpts.syslogRemote != "" {
-		switch strings.ToLower(logOpts.syslogProto) {
-		case "tcp", "udp":
-			logOpts.syslogProto = strings.ToLower(logOpts.syslogProto)
-		default:
-			time.Sleep(time.Second)
-			l.WithField("protocol", logOpts.syslogProto).Fatal("unknown syslog protocol")
-		}
-		hook, err := logrus_syslog.NewSyslogHook(
-			logOpts.syslogProto,
-			logOpts.syslogRemote,
-			syslog.LOG_DAEMON|syslog.LOG_INFO,
-			logOpts.syslogTag)
-		if err != nil {
-			time.Sleep(time.Second)
-			l.WithError(err).Fatal("unable to setup remote syslog")
-		} else {
-			l.Hooks.Add(hook)
-		}
+	sinks := openRecordSinks(logOpts.syslogTag, func(msg string, err error) {
+		time.Sleep(time.Second)
+		l.WithError(err).Fatal(msg)
+	})
+	if len(sinks) > 0 {
+		l.Hooks.Add(recordHook{sinks})
 	}
 
 	if logOpts.noLocal {
@@ -155,6 +224,11 @@ func implSetup() Logger {
 		} else {
 			l.Out = f
 		}
+	} else if out, isFile, err := localOutput(); err != nil {
+		time.Sleep(time.Second)
+		l.WithError(err).Fatal("unable to open log file")
+	} else if isFile {
+		l.Out = out
 	}
 
 	stdlog.SetFlags(0)
@@ -0,0 +1,203 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package logging
+
+import (
+	"flag"
+	"sort"
+	"sync"
+	"time"
+)
+
+// With -log.ratelimit.burst N, each distinct message (per level) is logged at
+// most N times per -log.ratelimit.interval; the rest are dropped, and at the
+// end of each interval there's one summary line per message saying how many
+// were suppressed.  Messages are constant strings in our code, with anything
+// variable in fields, so the message is a good key for "the same thing
+// again".
+//
+// That's not true of request logging, where one message covers every page
+// and response, and where the errors and slow requests must always be logged;
+// so that uses Unlimited, and has its own sampling instead.
+
+var rateLimitOpts struct {
+	burst    int
+	interval time.Duration
+}
+
+func init() {
+	flag.IntVar(&rateLimitOpts.burst, "log.ratelimit.burst", 0, "log each distinct message at most this many times per interval (0 for no limit)")
+	flag.DurationVar(&rateLimitOpts.interval, "log.ratelimit.interval", time.Minute, "interval for -log.ratelimit.burst")
+}
+
+type rateLimitKey struct {
+	level   Level
+	message string
+}
+
+type rateLimitBucket struct {
+	windowStart time.Time
+	count       int
+	suppressed  int
+}
+
+type rateLimiter struct {
+	burst    int
+	interval time.Duration
+	summary  Logger // unlimited, for the summaries
+
+	mu      sync.Mutex
+	buckets map[rateLimitKey]*rateLimitBucket
+}
+
+// withRateLimit wraps the logger if rate-limiting is configured.
+func withRateLimit(l Logger) Logger {
+	if rateLimitOpts.burst <= 0 || rateLimitOpts.interval <= 0 || l.IsDisabled() {
+		return l
+	}
+	rl := &rateLimiter{
+		burst:    rateLimitOpts.burst,
+		interval: rateLimitOpts.interval,
+		summary:  l,
+		buckets:  make(map[rateLimitKey]*rateLimitBucket),
+	}
+	go rl.summarize()
+	return rateLimitedLogger{Logger: l, rl: rl}
+}
+
+func (rl *rateLimiter) allow(level Level, message string) bool {
+	if level < implLevel() {
+		// The backend will drop it anyway; don't count it.
+		return true
+	}
+	key := rateLimitKey{level, message}
+	now := time.Now()
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	b := rl.buckets[key]
+	if b == nil {
+		b = &rateLimitBucket{windowStart: now}
+		rl.buckets[key] = b
+	} else if now.Sub(b.windowStart) >= rl.interval {
+		b.windowStart, b.count = now, 0
+	}
+	b.count++
+	if b.count <= rl.burst {
+		return true
+	}
+	b.suppressed++
+	return false
+}
+
+// summarize logs the suppression counts once per interval, and forgets
+// messages which have gone quiet.
+func (rl *rateLimiter) summarize() {
+	type summary struct {
+		key        rateLimitKey
+		suppressed int
+	}
+	for range time.Tick(rl.interval) {
+		var out []summary
+		now := time.Now()
+		rl.mu.Lock()
+		for key, b := range rl.buckets {
+			if b.suppressed > 0 {
+				out = append(out, summary{key, b.suppressed})
+				b.suppressed = 0
+			} else if now.Sub(b.windowStart) >= 2*rl.interval {
+				delete(rl.buckets, key)
+			}
+		}
+		rl.mu.Unlock()
+
+		sort.Slice(out, func(i, j int) bool { return out[i].key.message < out[j].key.message })
+		for _, s := range out {
+			rl.summary.
+				WithField("message", s.key.message).
+				WithField("message_level", s.key.level.String()).
+				WithField("suppressed", s.suppressed).
+				WithField("interval", rl.interval.String()).
+				Warning("suppressed repeated log messages")
+		}
+	}
+}
+
+// Unlimited returns the logger without any rate limit, keeping its fields.
+func Unlimited(l Logger) Logger {
+	if u, ok := l.(interface{ unlimited() Logger }); ok {
+		return u.unlimited()
+	}
+	return l
+}
+
+// rateLimitedLogger drops messages beyond the rate limit.
+type rateLimitedLogger struct {
+	Logger
+	rl *rateLimiter
+}
+
+func (r rateLimitedLogger) unlimited() Logger { return r.Logger }
+
+// WithField adds a k/v pair, keeping the rate limit.
+func (r rateLimitedLogger) WithField(key string, value interface{}) Logger {
+	return rateLimitedLogger{r.Logger.WithField(key, value), r.rl}
+}
+
+// WithError adds an error, keeping the rate limit.
+func (r rateLimitedLogger) WithError(err error) Logger {
+	return rateLimitedLogger{r.Logger.WithError(err), r.rl}
+}
+
+// Str adds a string field, keeping the rate limit.
+func (r rateLimitedLogger) Str(key, value string) Logger {
+	return rateLimitedLogger{r.Logger.Str(key, value), r.rl}
+}
+
+// Int adds an integer field, keeping the rate limit.
+func (r rateLimitedLogger) Int(key string, value int64) Logger {
+	return rateLimitedLogger{r.Logger.Int(key, value), r.rl}
+}
+
+// Dur adds a duration field, keeping the rate limit.
+func (r rateLimitedLogger) Dur(key string, value time.Duration) Logger {
+	return rateLimitedLogger{r.Logger.Dur(key, value), r.rl}
+}
+
+// Bool adds a boolean field, keeping the rate limit.
+func (r rateLimitedLogger) Bool(key string, value bool) Logger {
+	return rateLimitedLogger{r.Logger.Bool(key, value), r.rl}
+}
+
+// Err is WithError.
+func (r rateLimitedLogger) Err(err error) Logger { return r.WithError(err) }
+
+// Fields adds several fields, keeping the rate limit.
+func (r rateLimitedLogger) Fields(fields ...Field) Logger {
+	return rateLimitedLogger{r.Logger.Fields(fields...), r.rl}
+}
+
+func (r rateLimitedLogger) Debug(message string) {
+	if r.rl.allow(LevelDebug, message) {
+		r.Logger.Debug(message)
+	}
+}
+
+func (r rateLimitedLogger) Info(message string) {
+	if r.rl.allow(LevelInfo, message) {
+		r.Logger.Info(message)
+	}
+}
+
+func (r rateLimitedLogger) Warning(message string) {
+	if r.rl.allow(LevelWarning, message) {
+		r.Logger.Warning(message)
+	}
+}
+
+func (r rateLimitedLogger) Error(message string) {
+	if r.rl.allow(LevelError, message) {
+		r.Logger.Error(message)
+	}
+}
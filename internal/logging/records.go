@@ -0,0 +1,125 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Some outputs want each log event as structured data, rather than as a
+// formatted line: remote syslog, journald and GELF.  We call those record
+// sinks.  logrus feeds them from a hook; zerolog and slog write JSON lines,
+// which we parse back into fields with jsonRecordWriter.  Either way the
+// backends don't need to know about the individual sinks.
+
+// recordSink is an output taking a level name, as the backend spells it, a
+// message and the fields.  It must not modify the fields, and may keep them
+// after returning, so callers must not modify them either.
+type recordSink interface {
+	sendRecord(level, message string, fields map[string]interface{}) error
+}
+
+// recordSinksConfigured says whether any record sink is wanted, for the
+// backends' Enabled().
+func recordSinksConfigured() bool {
+	return journaldOpts.enabled || gelfOpts.address != "" || syslogOpts.address != ""
+}
+
+// openRecordSinks opens all the configured record sinks, each behind its own
+// asynchronous queue.  On failure it calls failed, which may be fatal, and
+// carries on without that sink.
+func openRecordSinks(tag string, failed func(msg string, err error)) []recordSink {
+	var sinks []recordSink
+	add := func(name string, s recordSink, err error) {
+		if err == nil {
+			s, err = newAsyncSink(name, s)
+		}
+		if err != nil {
+			failed("unable to setup "+name, err)
+			return
+		}
+		sinks = append(sinks, s)
+	}
+	if syslogOpts.address != "" {
+		s, err := newSyslogSink(tag)
+		add("syslog", s, err)
+	}
+	if journaldOpts.enabled {
+		s, err := newJournalSink(journaldOpts.socket, tag)
+		add("journald", s, err)
+	}
+	if gelfOpts.address != "" {
+		s, err := newGELFSink()
+		add("gelf", s, err)
+	}
+	return sinks
+}
+
+// Syslog priorities, which journald and GELF both use as levels.
+const (
+	priCrit    = 2
+	priErr     = 3
+	priWarning = 4
+	priInfo    = 6
+	priDebug   = 7
+)
+
+// syslogPriority maps the level names used by the backends.  Matching the
+// logrus syslog hook, both fatal and panic are critical.
+func syslogPriority(level string) int {
+	switch strings.ToLower(level) {
+	case "trace", "debug":
+		return priDebug
+	case "warn", "warning":
+		return priWarning
+	case "error", "err":
+		return priErr
+	case "fatal", "panic":
+		return priCrit
+	}
+	return priInfo
+}
+
+// jsonRecordWriter is an io.Writer taking the JSON lines of zerolog or slog
+// and sending each as a record, with messageKey as the message and every
+// other key as a field, except for the timestamp, since the sinks have their
+// own.
+type jsonRecordWriter struct {
+	sinks      []recordSink
+	messageKey string
+}
+
+func (jw jsonRecordWriter) Write(p []byte) (int, error) {
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	for dec.More() {
+		var data map[string]interface{}
+		if err := dec.Decode(&data); err != nil {
+			// Not JSON, such as from a stdlog diversion; send as-is.
+			return len(p), jw.send("info", strings.TrimRight(string(p), "\n"), nil)
+		}
+		message, _ := data[jw.messageKey].(string)
+		level, _ := data["level"].(string)
+		delete(data, jw.messageKey)
+		delete(data, "level")
+		delete(data, "time")
+		if err := jw.send(level, message, data); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (jw jsonRecordWriter) send(level, message string, fields map[string]interface{}) error {
+	var firstErr error
+	for _, s := range jw.sinks {
+		if err := s.sendRecord(level, message, fields); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,308 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package logging
+
+import (
+	"flag"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Redaction keeps secrets out of the logs.  -log.redact is a list of
+// case-insensitive glob patterns which are matched against field keys, query
+// parameter names and header names; for a dotted key such as those from
+// slog groups, the last component is also tried.  Anything matching is
+// logged as Redacted instead.
+//
+// Setup applies this to every WithField, and to values which we know how to
+// look inside: URLs, query values, headers and *url.Error.  Code which logs
+// such things as plain strings should use RedactQuery or RedactURL itself.
+
+// Redacted replaces the values of anything sensitive.
+const Redacted = "[REDACTED]"
+
+const defaultRedactPatterns = "*token*,*password*,passwd,*secret*,*signature*,sig,*session*,*credential*," +
+	"authorization,proxy-authorization,cookie,set-cookie,api_key,apikey,api-key,x-api-key,*_key,access_key"
+
+var redactOpts struct {
+	patterns patternListFlag
+}
+
+func init() {
+	redactOpts.patterns.Set(defaultRedactPatterns)
+	flag.Var(&redactOpts.patterns, "log.redact", "comma-separated glob patterns of field keys, query parameters and headers whose values to redact (empty to disable)")
+}
+
+// patternListFlag is a flag.Value for a comma-separated list of patterns,
+// replaced wholesale when set, so that the default can be overridden.
+type patternListFlag []string
+
+func (p *patternListFlag) String() string {
+	if p == nil {
+		return ""
+	}
+	return strings.Join(*p, ",")
+}
+
+func (p *patternListFlag) Set(value string) error {
+	defer resetRedactCache()
+	*p = nil
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		if _, err := path.Match(item, ""); err != nil {
+			return err
+		}
+		*p = append(*p, item)
+	}
+	return nil
+}
+
+// redactCache remembers ShouldRedact's answer for each key, since matching
+// every pattern for every field of every log line is most of the cost of
+// logging through Setup's wrappers.  Query parameter and header names come
+// from clients, so it's bounded; past that, keys are just matched each time.
+var redactCache struct {
+	sync.RWMutex
+	keys map[string]bool
+}
+
+const redactCacheMaxKeys = 4096
+
+// resetRedactCache is for when the patterns change.
+func resetRedactCache() {
+	redactCache.Lock()
+	redactCache.keys = nil
+	redactCache.Unlock()
+}
+
+// ShouldRedact says whether the value for the key should be redacted.
+func ShouldRedact(key string) bool {
+	if len(redactOpts.patterns) == 0 {
+		return false
+	}
+	redactCache.RLock()
+	redact, ok := redactCache.keys[key]
+	redactCache.RUnlock()
+	if ok {
+		return redact
+	}
+	redact = matchRedactPatterns(key)
+	redactCache.Lock()
+	if redactCache.keys == nil {
+		redactCache.keys = make(map[string]bool)
+	}
+	if len(redactCache.keys) < redactCacheMaxKeys {
+		redactCache.keys[key] = redact
+	}
+	redactCache.Unlock()
+	return redact
+}
+
+func matchRedactPatterns(key string) bool {
+	key = strings.ToLower(key)
+	last := key
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		last = key[i+1:]
+	}
+	for _, p := range redactOpts.patterns {
+		if ok, _ := path.Match(p, key); ok {
+			return true
+		}
+		if last != key {
+			if ok, _ := path.Match(p, last); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RedactQuery redacts the values of sensitive parameters in a raw query
+// string, otherwise leaving it exactly as it was.
+func RedactQuery(rawQuery string) string {
+	if rawQuery == "" || len(redactOpts.patterns) == 0 {
+		return rawQuery
+	}
+	parts := strings.Split(rawQuery, "&")
+	changed := false
+	for i, part := range parts {
+		k, _, hasValue := strings.Cut(part, "=")
+		if uk, err := url.QueryUnescape(k); err == nil {
+			k = uk
+		}
+		if hasValue && ShouldRedact(k) {
+			parts[i] = part[:strings.IndexByte(part, '=')+1] + Redacted
+			changed = true
+		}
+	}
+	if !changed {
+		return rawQuery
+	}
+	return strings.Join(parts, "&")
+}
+
+// RedactURL renders the URL with any password and sensitive query values
+// redacted.
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	c := *u
+	c.RawQuery = RedactQuery(c.RawQuery)
+	return c.Redacted()
+}
+
+// RedactRequestURI redacts the query of a request-target, such as
+// http.Request.RequestURI, as the client sent it.
+func RedactRequestURI(uri string) string {
+	if p, q, ok := strings.Cut(uri, "?"); ok {
+		return p + "?" + RedactQuery(q)
+	}
+	return uri
+}
+
+// RedactURLString is RedactURL for a URL we have as a string; if it can't be
+// parsed, it's redacted entirely rather than risk it.
+func RedactURLString(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return Redacted
+	}
+	return RedactURL(u)
+}
+
+func redactValue(key string, value interface{}) interface{} {
+	if ShouldRedact(key) {
+		return Redacted
+	}
+	switch v := value.(type) {
+	case *url.URL:
+		return RedactURL(v)
+	case url.URL:
+		return RedactURL(&v)
+	case url.Values:
+		out := make(url.Values, len(v))
+		for k, vv := range v {
+			if ShouldRedact(k) {
+				out[k] = []string{Redacted}
+			} else {
+				out[k] = vv
+			}
+		}
+		return out
+	case http.Header:
+		out := make(http.Header, len(v))
+		for k, vv := range v {
+			if ShouldRedact(k) {
+				out[k] = []string{Redacted}
+			} else {
+				out[k] = vv
+			}
+		}
+		return out
+	case error:
+		return redactError(v)
+	}
+	return value
+}
+
+// redactError fixes up the URL in a *url.Error, which is what net/http
+// clients return, and which would otherwise log the full request URL.
+func redactError(err error) error {
+	ue, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+	if r := RedactURLString(ue.URL); r != ue.URL {
+		return &url.Error{Op: ue.Op, URL: r, Err: ue.Err}
+	}
+	return err
+}
+
+// withRedaction wraps the logger if there's anything to redact.
+func withRedaction(l Logger) Logger {
+	if len(redactOpts.patterns) == 0 || l.IsDisabled() {
+		return l
+	}
+	return redactingLogger{l}
+}
+
+// redactingLogger redacts field values on the way in.
+type redactingLogger struct {
+	Logger
+}
+
+// WithField adds a k/v pair, with the value redacted if need be.
+func (r redactingLogger) WithField(key string, value interface{}) Logger {
+	return redactingLogger{r.Logger.WithField(key, redactValue(key, value))}
+}
+
+// WithError adds an error, with any URL in it redacted.
+func (r redactingLogger) WithError(err error) Logger {
+	return redactingLogger{r.Logger.WithError(redactError(err))}
+}
+
+// Str adds a string field, redacted if need be.
+func (r redactingLogger) Str(key, value string) Logger {
+	if ShouldRedact(key) {
+		value = Redacted
+	}
+	return redactingLogger{r.Logger.Str(key, value)}
+}
+
+// Int adds an integer field, redacted if need be.
+func (r redactingLogger) Int(key string, value int64) Logger {
+	if ShouldRedact(key) {
+		return redactingLogger{r.Logger.Str(key, Redacted)}
+	}
+	return redactingLogger{r.Logger.Int(key, value)}
+}
+
+// Dur adds a duration field, redacted if need be.
+func (r redactingLogger) Dur(key string, value time.Duration) Logger {
+	if ShouldRedact(key) {
+		return redactingLogger{r.Logger.Str(key, Redacted)}
+	}
+	return redactingLogger{r.Logger.Dur(key, value)}
+}
+
+// Bool adds a boolean field, redacted if need be.
+func (r redactingLogger) Bool(key string, value bool) Logger {
+	if ShouldRedact(key) {
+		return redactingLogger{r.Logger.Str(key, Redacted)}
+	}
+	return redactingLogger{r.Logger.Bool(key, value)}
+}
+
+// Err is WithError.
+func (r redactingLogger) Err(err error) Logger { return r.WithError(err) }
+
+// Fields adds several fields, copying them only if any need redacting.
+func (r redactingLogger) Fields(fields ...Field) Logger {
+	out := fields
+	for i, f := range fields {
+		var rf Field
+		switch {
+		case ShouldRedact(f.Key):
+			rf = Str(f.Key, Redacted)
+		case f.kind == fieldAny:
+			rf = Any(f.Key, redactValue(f.Key, f.any))
+		default:
+			continue
+		}
+		if &out[0] == &fields[0] {
+			out = append([]Field(nil), fields...)
+		}
+		out[i] = rf
+	}
+	return redactingLogger{r.Logger.Fields(out...)}
+}
@@ -0,0 +1,371 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+//go:build slog && !logrus && !zerolog
+// +build slog,!logrus,!zerolog
+
+package logging
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	stdlog "log"
+	"log/slog"
+	"log/syslog"
+	"os"
+	"strings"
+	"time"
+
+	"go.pennock.tech/dummyapp/internal/version"
+)
+
+var logOpts struct {
+	level       string
+	json        bool
+	syslogLocal bool
+	syslogTag   string
+	noLocal     bool
+}
+
+func init() {
+	flag.StringVar(&logOpts.level, "log.level", "info", "logging level, optionally with levels for components, such as info,stats=debug")
+	flag.BoolVar(&logOpts.json, "log.json", false, "format logs into JSON")
+	flag.BoolVar(&logOpts.noLocal, "log.no-local", false, "inhibit stdio logging, only use any log hooks (syslog)")
+	flag.BoolVar(&logOpts.syslogLocal, "log.syslog.local", false, "log to local syslog")
+	flag.StringVar(&logOpts.syslogTag, "log.syslog.tag", version.Program, "tag for syslog messages")
+}
+
+// Enabled is a predicate stating if logging is enabled.
+// It has to be usable after flags init but _before_ implSetup, being called by
+// Setup() in core.go to decide if we should be called.
+func Enabled() bool {
+	return !logOpts.noLocal || logOpts.syslogLocal || recordSinksConfigured()
+}
+
+// Used by Setup() to log which we are:
+const implPackage = "slog"
+
+// slogLevel is shared by all our handlers, for runtime level changes.
+var slogLevel slog.LevelVar
+
+func implLevel() Level {
+	switch lvl := slogLevel.Level(); {
+	case lvl < slog.LevelInfo:
+		return LevelDebug
+	case lvl < slog.LevelWarn:
+		return LevelInfo
+	case lvl < slog.LevelError:
+		return LevelWarning
+	default:
+		return LevelError
+	}
+}
+
+func implSetLevel(level Level) {
+	switch level {
+	case LevelDebug:
+		slogLevel.Set(slog.LevelDebug)
+	case LevelInfo:
+		slogLevel.Set(slog.LevelInfo)
+	case LevelWarning:
+		slogLevel.Set(slog.LevelWarn)
+	default:
+		slogLevel.Set(slog.LevelError)
+	}
+}
+
+// -------------------------8< wrap slog type >8---------------------------
+
+type wrapSlog struct {
+	*slog.Logger
+}
+
+// WithField adds a k/v pair to the accumulated logging details.
+func (w wrapSlog) WithField(key string, value interface{}) Logger {
+	return wrapSlog{w.Logger.With(slog.Any(key, value))}
+}
+
+// WithError adds an error to the accumulated logging details.
+func (w wrapSlog) WithError(err error) Logger {
+	return wrapSlog{w.Logger.With(slog.Any("error", err))}
+}
+
+// Str adds a string field.
+func (w wrapSlog) Str(key, value string) Logger {
+	return wrapSlog{w.Logger.With(slog.String(key, value))}
+}
+
+// Int adds an integer field.
+func (w wrapSlog) Int(key string, value int64) Logger {
+	return wrapSlog{w.Logger.With(slog.Int64(key, value))}
+}
+
+// Dur adds a duration field, as microseconds.
+func (w wrapSlog) Dur(key string, value time.Duration) Logger {
+	return wrapSlog{w.Logger.With(slog.Float64(key, durationMicros(value)))}
+}
+
+// Bool adds a boolean field.
+func (w wrapSlog) Bool(key string, value bool) Logger {
+	return wrapSlog{w.Logger.With(slog.Bool(key, value))}
+}
+
+// Err is WithError.
+func (w wrapSlog) Err(err error) Logger { return w.WithError(err) }
+
+// Fields adds several fields at once.
+func (w wrapSlog) Fields(fields ...Field) Logger {
+	attrs := make([]any, len(fields))
+	for i, f := range fields {
+		switch f.kind {
+		case fieldString:
+			attrs[i] = slog.String(f.Key, f.str)
+		case fieldInt:
+			attrs[i] = slog.Int64(f.Key, f.num)
+		case fieldBool:
+			attrs[i] = slog.Bool(f.Key, f.num != 0)
+		default:
+			attrs[i] = slog.Any(f.Key, f.value())
+		}
+	}
+	return wrapSlog{w.Logger.With(attrs...)}
+}
+
+// Debug logs at debug level.
+func (w wrapSlog) Debug(message string) { w.Logger.Debug(message) }
+
+// Info logs at info level.
+func (w wrapSlog) Info(message string) { w.Logger.Info(message) }
+
+// Warning logs at warn level.
+func (w wrapSlog) Warning(message string) { w.Logger.Warn(message) }
+
+// Error logs at error level.
+func (w wrapSlog) Error(message string) { w.Logger.Error(message) }
+
+// IsDisabled is always false for an slog logger.
+// Note that our level can be set to disabled, but in that case we return a
+// nilLogger.
+func (w wrapSlog) IsDisabled() bool { return false }
+
+// AttachToContext returns the context unchanged: slog has no facility for
+// retrieving a logger from a context.
+func (w wrapSlog) AttachToContext(ctx context.Context) context.Context { return ctx }
+
+// slogHandler lets NewSlogHandler hand out our handler directly.
+func (w wrapSlog) slogHandler() slog.Handler { return w.Logger.Handler() }
+
+// -------------------------8< wrap slog type >8---------------------------
+
+func ourFatalf(spec string, args ...interface{}) {
+	time.Sleep(time.Second)
+	fmt.Fprintf(os.Stderr, spec, args...)
+	flushBeforeExit()
+	os.Exit(1)
+}
+
+// replaceLevel renders levels the way logrus does, so that switching backend
+// doesn't break log queries: lower-case, and "warning" not "warn".
+func replaceLevel(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 || a.Key != slog.LevelKey {
+		return a
+	}
+	lvl, ok := a.Value.Any().(slog.Level)
+	if !ok {
+		return a
+	}
+	switch lvl {
+	case slog.LevelWarn:
+		return slog.String(a.Key, "warning")
+	default:
+		return slog.String(a.Key, strings.ToLower(lvl.String()))
+	}
+}
+
+// implSetup sets up logging and is called by Setup (usually).
+//
+// If logging can't be set-up, please assume that this is fatal and abort; we
+// don't run without an audit trail going where it is supposed to go.
+// If a network setup fails initial setup when called and returns an error,
+// then so be it: we're a finger service, not critical plumbing infrastructure
+// which must come up so that other things can come up.  Don't add complexity.
+//
+// Recommend a sleep before Fatal so that if we keep dying, we don't die in a
+// fast loop and chew system resources.
+func implSetup() Logger {
+	base, components, err := parseLevelSpec(logOpts.level)
+	if err != nil {
+		ourFatalf("unable to parse logging level: %v\n", err)
+	}
+	var lvl slog.Level
+	switch strings.ToLower(base) {
+	case "debug":
+		lvl = slog.LevelDebug
+	case "info", "":
+		lvl = slog.LevelInfo
+	case "warn", "warning":
+		lvl = slog.LevelWarn
+	case "error", "err":
+		lvl = slog.LevelError
+	case "none", "disable", "disabled":
+		return newNilLoggerDisablingLog()
+	default:
+		ourFatalf("unable to parse logging level, %q unrecognized\n", base)
+	}
+
+	slogLevel.Set(lvl)
+	setComponentLevels(components)
+	opts := &slog.HandlerOptions{Level: &slogLevel, ReplaceAttr: replaceLevel}
+	newHandler := func(w io.Writer) slog.Handler {
+		if logOpts.json {
+			return slog.NewJSONHandler(w, opts)
+		}
+		return slog.NewTextHandler(w, opts)
+	}
+
+	var handlers fanoutHandler
+	if !logOpts.noLocal {
+		local, _, err := localOutput()
+		if err != nil {
+			ourFatalf("unable to open log file: %v\n", err)
+		}
+		handlers = append(handlers, newHandler(local))
+	}
+
+	if logOpts.syslogLocal {
+		w, err := syslog.New(syslog.LOG_INFO, logOpts.syslogTag)
+		if err != nil {
+			// We're still logging to stderr, unless told not to, in which
+			// case this goes nowhere, as with the other backends.
+			slog.New(handlers).Error("unable to setup local syslog", slog.Any("error", err))
+		} else {
+			handlers = append(handlers, newSyslogHandler(w, newHandler))
+		}
+	}
+
+	sinks := openRecordSinks(logOpts.syslogTag, func(msg string, err error) {
+		ourFatalf("%s: %v\n", msg, err)
+	})
+	if len(sinks) > 0 {
+		jw := jsonRecordWriter{sinks: sinks, messageKey: slog.MessageKey}
+		handlers = append(handlers, slog.NewJSONHandler(jw, opts))
+	}
+
+	var h slog.Handler = handlers
+	if len(handlers) == 1 {
+		h = handlers[0]
+	}
+	l := slog.New(h)
+
+	stdlog.SetFlags(0)
+	stdlog.SetOutput(slog.NewLogLogger(l.With(slog.String("via", "stdlog")).Handler(), slog.LevelInfo).Writer())
+	return wrapSlog{l}
+}
+
+// fanoutHandler sends each record to all of its handlers.
+type fanoutHandler []slog.Handler
+
+func (f fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var firstErr error
+	for _, h := range f {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, r.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (f fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(fanoutHandler, len(f))
+	for i := range f {
+		out[i] = f[i].WithAttrs(attrs)
+	}
+	return out
+}
+
+func (f fanoutHandler) WithGroup(name string) slog.Handler {
+	out := make(fanoutHandler, len(f))
+	for i := range f {
+		out[i] = f[i].WithGroup(name)
+	}
+	return out
+}
+
+// syslogHandler formats records with one handler per syslog severity, since
+// the severity is chosen by which method of the syslog.Writer is called and
+// an io.Writer doesn't get told the level.
+type syslogHandler struct {
+	debug, info, warn, err slog.Handler
+}
+
+type syslogLevelWriter func(string) error
+
+func (s syslogLevelWriter) Write(p []byte) (int, error) {
+	if err := s(string(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func newSyslogHandler(w *syslog.Writer, newHandler func(io.Writer) slog.Handler) *syslogHandler {
+	return &syslogHandler{
+		debug: newHandler(syslogLevelWriter(w.Debug)),
+		info:  newHandler(syslogLevelWriter(w.Info)),
+		warn:  newHandler(syslogLevelWriter(w.Warning)),
+		err:   newHandler(syslogLevelWriter(w.Err)),
+	}
+}
+
+func (s *syslogHandler) forLevel(level slog.Level) slog.Handler {
+	switch {
+	case level >= slog.LevelError:
+		return s.err
+	case level >= slog.LevelWarn:
+		return s.warn
+	case level >= slog.LevelInfo:
+		return s.info
+	default:
+		return s.debug
+	}
+}
+
+func (s *syslogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return s.forLevel(level).Enabled(ctx, level)
+}
+
+func (s *syslogHandler) Handle(ctx context.Context, r slog.Record) error {
+	return s.forLevel(r.Level).Handle(ctx, r)
+}
+
+func (s *syslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &syslogHandler{
+		debug: s.debug.WithAttrs(attrs),
+		info:  s.info.WithAttrs(attrs),
+		warn:  s.warn.WithAttrs(attrs),
+		err:   s.err.WithAttrs(attrs),
+	}
+}
+
+func (s *syslogHandler) WithGroup(name string) slog.Handler {
+	return &syslogHandler{
+		debug: s.debug.WithGroup(name),
+		info:  s.info.WithGroup(name),
+		warn:  s.warn.WithGroup(name),
+		err:   s.err.WithGroup(name),
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package logging
+
+import (
+	"context"
+	stdlog "log"
+	"log/slog"
+)
+
+// NewSlogHandler returns an slog.Handler which logs via our Logger, so that
+// third-party code which logs via log/slog ends up in the same pipeline as
+// everything else, with its attributes as fields.  Groups are flattened into
+// dotted field names.
+//
+// The record's timestamp is dropped, as our Logger adds its own; and since
+// our Logger has no way to ask whether a level is enabled, filtering by level
+// is left to the backend.
+func NewSlogHandler(l Logger) slog.Handler {
+	if p, ok := l.(slogHandlerProvider); ok {
+		return p.slogHandler()
+	}
+	return &slogBridge{l: l}
+}
+
+// slogHandlerProvider is implemented by backends which are already built on
+// log/slog, for which wrapping would just be overhead.
+type slogHandlerProvider interface {
+	slogHandler() slog.Handler
+}
+
+type slogBridge struct {
+	l      Logger
+	prefix string
+}
+
+func (b *slogBridge) Enabled(_ context.Context, _ slog.Level) bool {
+	return !b.l.IsDisabled()
+}
+
+func (b *slogBridge) Handle(_ context.Context, r slog.Record) error {
+	l := b.l
+	r.Attrs(func(a slog.Attr) bool {
+		l = withSlogAttr(l, b.prefix, a)
+		return true
+	})
+	switch {
+	case r.Level >= slog.LevelError:
+		l.Error(r.Message)
+	case r.Level >= slog.LevelWarn:
+		l.Warning(r.Message)
+	case r.Level >= slog.LevelInfo:
+		l.Info(r.Message)
+	default:
+		l.Debug(r.Message)
+	}
+	return nil
+}
+
+func (b *slogBridge) WithAttrs(attrs []slog.Attr) slog.Handler {
+	l := b.l
+	for _, a := range attrs {
+		l = withSlogAttr(l, b.prefix, a)
+	}
+	return &slogBridge{l: l, prefix: b.prefix}
+}
+
+func (b *slogBridge) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return b
+	}
+	return &slogBridge{l: b.l, prefix: b.prefix + name + "."}
+}
+
+// withSlogAttr adds one attribute as a field, following the slog rules for
+// Handlers: empty attributes are ignored, and groups with an empty key are
+// inlined.
+func withSlogAttr(l Logger, prefix string, a slog.Attr) Logger {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return l
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			l = withSlogAttr(l, prefix, ga)
+		}
+		return l
+	}
+	if err, ok := a.Value.Any().(error); ok {
+		// slog code conventionally uses "err"; map that onto our error field.
+		if prefix == "" && (a.Key == "err" || a.Key == "error") {
+			return l.WithError(err)
+		}
+		return l.WithField(prefix+a.Key, err.Error())
+	}
+	return l.WithField(prefix+a.Key, a.Value.Any())
+}
+
+// setSlogDefault points the slog default logger at our Logger.  slog.SetDefault
+// also diverts the stdlib log package, which the backends have already set up
+// to their own taste, so we put that back afterwards.
+func setSlogDefault(l Logger) {
+	w, flags := stdlog.Writer(), stdlog.Flags()
+	slog.SetDefault(slog.New(NewSlogHandler(l)))
+	stdlog.SetOutput(w)
+	stdlog.SetFlags(flags)
+}
@@ -0,0 +1,330 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package logging
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+)
+
+// Remote syslog is RFC 5424, with our fields as the parameters of one
+// structured data element, so that a collector can pick them out without
+// parsing the message.  Over TCP and TLS (RFC 5425) messages are framed with
+// octet counting, per RFC 6587; over UDP (RFC 5426) each is one datagram.
+//
+// This is a recordSink, so works with every backend, and nothing goes
+// through a text formatter, so no terminal colour codes leak through.
+//
+// If the remote goes away, we reconnect on the next message; the queue in
+// front of every record sink holds messages and retries while it's gone.
+//
+// Local syslog, where the backend supports it, is still the stdlib log/syslog,
+// since that's what local daemons expect on their socket.
+
+const (
+	syslogFacilityDaemon = 3
+	syslogDialTimeout    = 10 * time.Second
+	syslogWriteTimeout   = 10 * time.Second
+	// 32473 is the Private Enterprise Number reserved for documentation,
+	// RFC 5612; sites with their own should use it.
+	defaultSyslogSDID = "fields@32473"
+)
+
+var syslogOpts struct {
+	address       string
+	proto         string
+	sdID          string
+	tlsCA         string
+	tlsCert       string
+	tlsKey        string
+	tlsServerName string
+}
+
+func init() {
+	flag.StringVar(&syslogOpts.address, "log.syslog.address", "", "host:port to send logs to via syslog")
+	flag.StringVar(&syslogOpts.proto, "log.syslog.proto", "udp", "protocol to use; [udp, tcp, tls]")
+	flag.StringVar(&syslogOpts.sdID, "log.syslog.sd-id", defaultSyslogSDID, "RFC 5424 structured data ID for our fields")
+	flag.StringVar(&syslogOpts.tlsCA, "log.syslog.tls.ca", "", "PEM file of CAs to verify the syslog server against, instead of the system roots")
+	flag.StringVar(&syslogOpts.tlsCert, "log.syslog.tls.cert", "", "PEM file of client certificate for syslog over TLS")
+	flag.StringVar(&syslogOpts.tlsKey, "log.syslog.tls.key", "", "PEM file of client key for syslog over TLS")
+	flag.StringVar(&syslogOpts.tlsServerName, "log.syslog.tls.server-name", "", "name to verify in the syslog server certificate, if not the address host")
+}
+
+type syslogSink struct {
+	network       string
+	address       string
+	tlsConfig     *tls.Config
+	octetCounting bool
+
+	// RFC 5424 header fields, already made safe.
+	hostname string
+	appName  string
+	procID   string
+	sdID     string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newSyslogSink(tag string) (*syslogSink, error) {
+	s := &syslogSink{
+		address: syslogOpts.address,
+		appName: syslogHeaderField(tag, 48),
+		procID:  strconv.Itoa(os.Getpid()),
+	}
+	switch strings.ToLower(syslogOpts.proto) {
+	case "udp":
+		s.network = "udp"
+	case "tcp":
+		s.network = "tcp"
+		s.octetCounting = true
+	case "tls":
+		s.network = "tcp"
+		s.octetCounting = true
+		cfg, err := syslogTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		s.tlsConfig = cfg
+	default:
+		return nil, fmt.Errorf("unknown syslog protocol %q", syslogOpts.proto)
+	}
+	if s.sdID = syslogOpts.sdID; s.sdID == "" || len(s.sdID) > 32 || syslogSDName(s.sdID) != s.sdID {
+		return nil, fmt.Errorf("invalid syslog structured data ID %q", syslogOpts.sdID)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = ""
+	}
+	s.hostname = syslogHeaderField(hostname, 255)
+
+	// Fail now if the remote isn't there, as the other outputs do; after
+	// this, we reconnect as needed.
+	if err := s.dial(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func syslogTLSConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: syslogOpts.tlsServerName,
+	}
+	if cfg.ServerName == "" {
+		host, _, err := net.SplitHostPort(syslogOpts.address)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ServerName = host
+	}
+	if syslogOpts.tlsCA != "" {
+		pemData, err := os.ReadFile(syslogOpts.tlsCA)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pemData) {
+			return nil, fmt.Errorf("no certificates found in %q", syslogOpts.tlsCA)
+		}
+	}
+	switch {
+	case syslogOpts.tlsCert != "" && syslogOpts.tlsKey != "":
+		cert, err := tls.LoadX509KeyPair(syslogOpts.tlsCert, syslogOpts.tlsKey)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	case syslogOpts.tlsCert != "" || syslogOpts.tlsKey != "":
+		return nil, errors.New("syslog TLS client certificate and key must be given together")
+	}
+	return cfg, nil
+}
+
+func (s *syslogSink) dial() error {
+	dialer := &net.Dialer{Timeout: syslogDialTimeout}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if s.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, s.network, s.address, s.tlsConfig)
+	} else {
+		conn, err = dialer.Dial(s.network, s.address)
+	}
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	return nil
+}
+
+// sendRecord makes syslogSink a recordSink.
+func (s *syslogSink) sendRecord(level, message string, fields map[string]interface{}) error {
+	msg := s.format(syslogPriority(level), message, fields)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(msg)
+}
+
+// write sends one message, connecting first if need be.  On failure the
+// connection is discarded, to be redialled next time.  Callers hold mu.
+func (s *syslogSink) write(msg []byte) error {
+	if s.conn != nil && s.octetCounting && s.peerClosed() {
+		s.conn.Close()
+		s.conn = nil
+	}
+	if s.conn == nil {
+		if err := s.dial(); err != nil {
+			return err
+		}
+	}
+	if s.octetCounting {
+		framed := strconv.AppendInt(make([]byte, 0, len(msg)+8), int64(len(msg)), 10)
+		framed = append(framed, ' ')
+		msg = append(framed, msg...)
+	}
+	_ = s.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+	if _, err := s.conn.Write(msg); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+// peerClosed checks for the remote having closed the stream, which otherwise
+// we would only learn of after losing a message to a successful write.  Syslog
+// servers never send us anything, so a peek finding anything but "nothing
+// yet" means the connection is gone.
+func (s *syslogSink) peerClosed() bool {
+	conn := s.conn
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return false
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return false
+	}
+	closed := false
+	_ = raw.Read(func(fd uintptr) bool {
+		var b [1]byte
+		n, _, err := syscall.Recvfrom(int(fd), b[:], syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+		closed = n == 0 && !errors.Is(err, syscall.EAGAIN)
+		return true
+	})
+	return closed
+}
+
+// format renders an RFC 5424 message:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (s *syslogSink) format(severity int, message string, fields map[string]interface{}) []byte {
+	b := make([]byte, 0, 256)
+	b = fmt.Appendf(b, "<%d>1 %s %s %s %s - ",
+		syslogFacilityDaemon*8+severity,
+		time.Now().UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		s.hostname, s.appName, s.procID)
+	b = appendStructuredData(b, s.sdID, fields)
+	if message != "" {
+		b = append(b, ' ')
+		if !isASCII(message) {
+			// MSG in UTF-8 must start with a BOM.
+			b = append(b, "\xef\xbb\xbf"...)
+		}
+		b = append(b, message...)
+	}
+	return b
+}
+
+// appendStructuredData puts all the fields into one element, sorted for
+// stable output.
+func appendStructuredData(b []byte, sdID string, fields map[string]interface{}) []byte {
+	if len(fields) == 0 {
+		return append(b, '-')
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	b = append(b, '[')
+	b = append(b, sdID...)
+	for _, k := range keys {
+		name := syslogSDName(k)
+		if len(name) > 32 {
+			name = name[:32]
+		}
+		b = append(b, ' ')
+		b = append(b, name...)
+		b = append(b, '=', '"')
+		for _, r := range journalValue(fields[k]) {
+			if r == '"' || r == '\\' || r == ']' {
+				b = append(b, '\\')
+			}
+			b = utf8.AppendRune(b, r)
+		}
+		b = append(b, '"')
+	}
+	return append(b, ']')
+}
+
+// syslogSDName makes a key usable as an SD-NAME: printable ASCII, without
+// '=', space, ']' or '"'.
+func syslogSDName(key string) string {
+	if key == "" {
+		return "_"
+	}
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c <= ' ' || c > '~' || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// syslogHeaderField makes a header field printable ASCII without spaces,
+// with the nil value "-" if empty.
+func syslogHeaderField(value string, maxLen int) string {
+	if value == "" {
+		return "-"
+	}
+	var b strings.Builder
+	for i := 0; i < len(value) && b.Len() < maxLen; i++ {
+		c := value[i]
+		if c <= ' ' || c > '~' {
+			c = '_'
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
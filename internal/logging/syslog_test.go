@@ -0,0 +1,187 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package logging
+
+import (
+	"errors"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendStructuredData(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		fields map[string]interface{}
+		want   string
+	}{
+		{"none", nil, "-"},
+		{"empty", map[string]interface{}{}, "-"},
+		{"sorted", map[string]interface{}{"b": "2", "a": "1"}, `[fields@32473 a="1" b="2"]`},
+		{"escaped", map[string]interface{}{"v": `say "hi" \ [x]`}, `[fields@32473 v="say \"hi\" \\ [x\]"]`},
+		{"non-string", map[string]interface{}{"n": 42, "ok": true, "err": errors.New("boom")}, `[fields@32473 err="boom" n="42" ok="true"]`},
+		{"utf-8", map[string]interface{}{"s": "café ☕"}, `[fields@32473 s="café ☕"]`},
+		{"bad names", map[string]interface{}{`a b=c]"d`: "x", "": "y"}, `[fields@32473 _="y" a_b_c__d="x"]`},
+		{"long name", map[string]interface{}{strings.Repeat("k", 40): "x"}, `[fields@32473 ` + strings.Repeat("k", 32) + `="x"]`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := string(appendStructuredData(nil, defaultSyslogSDID, tc.fields)); got != tc.want {
+				t.Errorf("appendStructuredData = %s\nwant %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSyslogSDName(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"", "_"},
+		{"request", "request"},
+		{"url.path", "url.path"},
+		{"a b", "a_b"},
+		{"k=v", "k_v"},
+		{`q"]`, "q__"},
+		{"tab\there", "tab_here"},
+		{"ünï", "__n__"},
+	} {
+		if got := syslogSDName(tc.in); got != tc.want {
+			t.Errorf("syslogSDName(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestSyslogHeaderField(t *testing.T) {
+	for _, tc := range []struct {
+		in     string
+		maxLen int
+		want   string
+	}{
+		{"", 48, "-"},
+		{"dummyapp", 48, "dummyapp"},
+		{"my app", 48, "my_app"},
+		{"héllo", 48, "h__llo"},
+		{"abcdefgh", 4, "abcd"},
+	} {
+		if got := syslogHeaderField(tc.in, tc.maxLen); got != tc.want {
+			t.Errorf("syslogHeaderField(%q, %d) = %q, want %q", tc.in, tc.maxLen, got, tc.want)
+		}
+	}
+}
+
+func testSyslogSink() *syslogSink {
+	return &syslogSink{
+		hostname: "host.example",
+		appName:  "dummyapp",
+		procID:   "1234",
+		sdID:     defaultSyslogSDID,
+	}
+}
+
+var rfc5424Header = regexp.MustCompile(`^<(\d+)>1 (\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}Z) host\.example dummyapp 1234 - `)
+
+func TestSyslogFormat(t *testing.T) {
+	s := testSyslogSink()
+	for _, tc := range []struct {
+		name     string
+		severity int
+		message  string
+		fields   map[string]interface{}
+		pri      string
+		rest     string
+	}{
+		{"info", priInfo, "responded", map[string]interface{}{"code": 200}, "30", `[fields@32473 code="200"] responded`},
+		{"error without fields", priErr, "failed", nil, "27", `- failed`},
+		{"no message", priWarning, "", map[string]interface{}{"a": "b"}, "28", `[fields@32473 a="b"]`},
+		{"utf-8 message", priDebug, "naïve", nil, "31", "- \xef\xbb\xbfnaïve"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			before := time.Now().UTC().Truncate(time.Microsecond)
+			got := string(s.format(tc.severity, tc.message, tc.fields))
+			m := rfc5424Header.FindStringSubmatch(got)
+			if m == nil {
+				t.Fatalf("not RFC 5424: %q", got)
+			}
+			if m[1] != tc.pri {
+				t.Errorf("PRI = %s, want %s", m[1], tc.pri)
+			}
+			ts, err := time.Parse(time.RFC3339Nano, m[2])
+			if err != nil || ts.Before(before) || ts.After(time.Now()) {
+				t.Errorf("timestamp %s not now: %v", m[2], err)
+			}
+			if rest := got[len(m[0]):]; rest != tc.rest {
+				t.Errorf("after header: %q, want %q", rest, tc.rest)
+			}
+		})
+	}
+}
+
+func TestSyslogOctetCounting(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	s := testSyslogSink()
+	s.octetCounting = true
+	s.conn = client
+
+	received := make(chan string, 1)
+	go func() {
+		b, _ := io.ReadAll(server)
+		received <- string(b)
+	}()
+	for _, msg := range []string{"first", "second message", ""} {
+		if err := s.write([]byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client.Close()
+	if got, want := <-received, "5 first14 second message0 "; got != want {
+		t.Errorf("stream = %q, want %q", got, want)
+	}
+}
+
+func TestSyslogReconnectsAfterPeerClose(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	s := testSyslogSink()
+	s.network, s.address, s.octetCounting = "tcp", ln.Addr().String(), true
+	if err := s.dial(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { s.conn.Close() }()
+
+	first, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Close()
+	// Give the FIN time to arrive.
+	time.Sleep(50 * time.Millisecond)
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, _ := ln.Accept()
+		accepted <- c
+	}()
+	if err := s.write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	second := <-accepted
+	if second == nil {
+		t.Fatal("no reconnection")
+	}
+	defer second.Close()
+	_ = second.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 16)
+	n, err := io.ReadAtLeast(second, buf, len("5 hello"))
+	if err != nil || string(buf[:n]) != "5 hello" {
+		t.Errorf("after reconnect, read %q, %v", buf[:n], err)
+	}
+}
@@ -8,6 +8,7 @@
 package logging
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
@@ -23,25 +24,20 @@ import (
 )
 
 var logOpts struct {
-	level        string
-	json         bool
-	syslogLocal  bool
-	syslogRemote string
-	syslogProto  string
-	syslogTag    string
-	noLocal      bool
+	level       string
+	json        bool
+	syslogLocal bool
+	syslogTag   string
+	noLocal     bool
 }
 
 var enabledAtomic uint32
 
 func init() {
-	flag.StringVar(&logOpts.level, "log.level", "info", "logging level")
+	flag.StringVar(&logOpts.level, "log.level", "info", "logging level, optionally with levels for components, such as info,stats=debug")
 	flag.BoolVar(&logOpts.json, "log.json", false, "format logs into JSON")
 	flag.BoolVar(&logOpts.noLocal, "log.no-local", false, "inhibit stdio logging, only use any log hooks (syslog)")
 	flag.BoolVar(&logOpts.syslogLocal, "log.syslog.local", false, "log to local syslog")
-	flag.StringVar(&logOpts.syslogRemote, "log.syslog.address", "", "host:port to send logs to via syslog")
-	// We can add more variants, such as "rfcFOO", if needed:
-	flag.StringVar(&logOpts.syslogProto, "log.syslog.proto", "udp", "protocol to use; [udp, tcp]")
 	flag.StringVar(&logOpts.syslogTag, "log.syslog.tag", version.Program, "tag for syslog messages")
 }
 
@@ -49,21 +45,50 @@ func init() {
 // It has to be usable after flags init but _before_ implSetup, being called by
 // Setup() in core.go to decide if we should be called.
 func Enabled() bool {
-	return !logOpts.noLocal || logOpts.syslogLocal || logOpts.syslogRemote != ""
+	return !logOpts.noLocal || logOpts.syslogLocal || recordSinksConfigured()
 }
 
 // Used by Setup() to log which we are:
 const implPackage = "zerolog"
 
+// We filter with the global level rather than the level of our root logger,
+// because loggers derived from it each carry their own copy of the level, so
+// the global is the only place we can change it for all of them at once.
+
+func implLevel() Level {
+	switch lvl := zerolog.GlobalLevel(); {
+	case lvl <= zerolog.DebugLevel:
+		return LevelDebug
+	case lvl == zerolog.InfoLevel:
+		return LevelInfo
+	case lvl == zerolog.WarnLevel:
+		return LevelWarning
+	default:
+		return LevelError
+	}
+}
+
+func implSetLevel(level Level) {
+	switch level {
+	case LevelDebug:
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	case LevelInfo:
+		zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	case LevelWarning:
+		zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	default:
+		zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+	}
+}
+
 // ------------------------8< wrap zerolog type >8-------------------------
 
 type wrapZerolog struct {
 	zerolog.Logger
 }
 
-// These methods are not the most efficient for use in chaining; if we switch
-// to zerolog then rework the internal logging API to have a lower impedance
-// mismatch.
+// WithField has to go through .Interface(), which reflects and allocates;
+// the typed methods don't, and Fields copies the context once for several.
 
 // WithField adds a k/v pair to the accumulated logging details.
 func (w wrapZerolog) WithField(key string, value interface{}) Logger {
@@ -75,6 +100,49 @@ func (w wrapZerolog) WithError(err error) Logger {
 	return wrapZerolog{w.Logger.With().Err(err).Logger()}
 }
 
+// Str adds a string field.
+func (w wrapZerolog) Str(key, value string) Logger {
+	return wrapZerolog{w.Logger.With().Str(key, value).Logger()}
+}
+
+// Int adds an integer field.
+func (w wrapZerolog) Int(key string, value int64) Logger {
+	return wrapZerolog{w.Logger.With().Int64(key, value).Logger()}
+}
+
+// Dur adds a duration field, in microseconds per zerolog.DurationFieldUnit.
+func (w wrapZerolog) Dur(key string, value time.Duration) Logger {
+	return wrapZerolog{w.Logger.With().Dur(key, value).Logger()}
+}
+
+// Bool adds a boolean field.
+func (w wrapZerolog) Bool(key string, value bool) Logger {
+	return wrapZerolog{w.Logger.With().Bool(key, value).Logger()}
+}
+
+// Err is WithError.
+func (w wrapZerolog) Err(err error) Logger { return w.WithError(err) }
+
+// Fields adds several fields at once.
+func (w wrapZerolog) Fields(fields ...Field) Logger {
+	c := w.Logger.With()
+	for _, f := range fields {
+		switch f.kind {
+		case fieldString:
+			c = c.Str(f.Key, f.str)
+		case fieldInt:
+			c = c.Int64(f.Key, f.num)
+		case fieldDuration:
+			c = c.Dur(f.Key, time.Duration(f.num))
+		case fieldBool:
+			c = c.Bool(f.Key, f.num != 0)
+		default:
+			c = c.Interface(f.Key, f.any)
+		}
+	}
+	return wrapZerolog{c.Logger()}
+}
+
 func (w wrapZerolog) Debug(message string) {
 	w.Logger.Debug().Msg(message)
 }
@@ -93,11 +161,18 @@ func (w wrapZerolog) Error(message string) {
 // nilLogger.
 func (w wrapZerolog) IsDisabled() bool { return false }
 
+// AttachToContext stores the zerolog.Logger in the context, for retrieval by
+// zerolog.Ctx() in any library using zerolog directly.
+func (w wrapZerolog) AttachToContext(ctx context.Context) context.Context {
+	return w.Logger.WithContext(ctx)
+}
+
 // ------------------------8< wrap zerolog type >8-------------------------
 
 func ourFatalf(spec string, args ...interface{}) {
 	time.Sleep(time.Second)
 	fmt.Fprintf(os.Stderr, spec, args...)
+	flushBeforeExit()
 	os.Exit(1)
 }
 
@@ -125,8 +200,12 @@ func implSetup() Logger {
 		return wrapZerolog{l}
 	}
 
+	base, components, err := parseLevelSpec(logOpts.level)
+	if err != nil {
+		ourFatalf("unable to parse logging level: %v\n", err)
+	}
 	var lvl zerolog.Level
-	switch strings.ToLower(logOpts.level) {
+	switch strings.ToLower(base) {
 	case "debug":
 		lvl = zerolog.DebugLevel
 	case "info", "":
@@ -143,15 +222,27 @@ func implSetup() Logger {
 		lvl = zerolog.Disabled
 	// zerolog.NoLevel has no applicability here
 	default:
-		ourFatalf("unable to parse logging level, %q unrecognized\n", logOpts.level)
+		ourFatalf("unable to parse logging level, %q unrecognized\n", base)
 	}
 	if lvl == zerolog.Disabled {
 		return newNilLoggerDisablingLog()
 	}
 
-	var expectedNormalOutput io.Writer = os.Stderr
+	var (
+		local  io.Writer = os.Stderr
+		isFile bool
+	)
+	if !logOpts.noLocal {
+		var err error
+		if local, isFile, err = localOutput(); err != nil {
+			ourFatalf("unable to open log file: %v\n", err)
+		}
+	}
+	var expectedNormalOutput io.Writer = local
 
-	l := zerolog.New(expectedNormalOutput).With().Timestamp().Logger().Level(lvl)
+	zerolog.SetGlobalLevel(lvl)
+	setComponentLevels(components)
+	l := zerolog.New(expectedNormalOutput).With().Timestamp().Logger()
 
 	// compatibility with existing logs from logrus
 	zerolog.MessageFieldName = "msg"
@@ -169,35 +260,38 @@ func implSetup() Logger {
 		// we make no attempt to match layout etc of logrus: if you use the
 		// console logger, then you get what you get.  If consistency matters,
 		// then you should be using JSON-stream logging.
-		expectedNormalOutput = zerolog.ConsoleWriter{Out: os.Stderr}
+		expectedNormalOutput = zerolog.ConsoleWriter{Out: local, NoColor: isFile}
 		l = l.Output(expectedNormalOutput)
 	}
 
-	if logOpts.syslogLocal || logOpts.syslogRemote != "" {
-		var (
-			w       *syslog.Writer
-			err     error
-			failMsg string
-		)
-		if logOpts.syslogRemote != "" {
-			failMsg = "unable to dial remote syslog"
-			w, err = syslog.Dial(logOpts.syslogProto, logOpts.syslogRemote, syslog.LOG_INFO, logOpts.syslogTag)
-		} else {
-			failMsg = "unable to setup local syslog"
-			w, err = syslog.New(syslog.LOG_INFO, logOpts.syslogTag)
-		}
+	var outputs []io.Writer
+	if !logOpts.noLocal {
+		outputs = append(outputs, expectedNormalOutput)
+	}
+
+	if logOpts.syslogLocal {
+		w, err := syslog.New(syslog.LOG_INFO, logOpts.syslogTag)
 		if err != nil {
-			l.Error().Err(err).Msg(failMsg)
-			return setupStdlogAndDone(l)
-		}
-		if logOpts.noLocal {
-			l = l.Output(zerolog.SyslogLevelWriter(w))
+			l.Error().Err(err).Msg("unable to setup local syslog")
 		} else {
-			l = l.Output(zerolog.MultiLevelWriter(
-				expectedNormalOutput,
-				zerolog.SyslogLevelWriter(w),
-			))
+			outputs = append(outputs, zerolog.SyslogLevelWriter(w))
 		}
 	}
+
+	sinks := openRecordSinks(logOpts.syslogTag, func(msg string, err error) {
+		ourFatalf("%s: %v\n", msg, err)
+	})
+	if len(sinks) > 0 {
+		outputs = append(outputs, jsonRecordWriter{sinks: sinks, messageKey: zerolog.MessageFieldName})
+	}
+
+	switch {
+	case len(outputs) == 1 && !logOpts.noLocal:
+		// just the local output, already set
+	case len(outputs) == 1:
+		l = l.Output(outputs[0])
+	case len(outputs) > 1:
+		l = l.Output(zerolog.MultiLevelWriter(outputs...))
+	}
 	return setupStdlogAndDone(l)
 }
@@ -0,0 +1,69 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package stats
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"go.pennock.tech/dummyapp/internal/logging"
+)
+
+// Process-wide counters.  These don't hang off a Handle because the code
+// incrementing them should not need to care whether or not stats export was
+// started.
+//
+// We don't use the stdlib expvar: importing it registers /debug/vars on the
+// DefaultServeMux, showing the command line and memstats to anyone who can
+// reach that mux.  Instead Handler serves the counters, and the caller
+// decides who gets to see it.
+
+var counters struct {
+	sync.Mutex
+	panicsByPage map[string]uint64
+}
+
+// CountPanic records that a handler for the given page panicked.
+func CountPanic(page string) {
+	counters.Lock()
+	defer counters.Unlock()
+	if counters.panicsByPage == nil {
+		counters.panicsByPage = make(map[string]uint64)
+	}
+	counters.panicsByPage[page]++
+}
+
+// Counters is a snapshot of the process-wide counters.
+type Counters struct {
+	PanicsByPage map[string]uint64 `json:"panics_by_page"`
+	// The queue depths and drop counts of the remote log outputs live in
+	// the logging package; we just publish them.
+	LogOutputs map[string]logging.AsyncOutputStats `json:"log_outputs"`
+}
+
+// Snapshot returns the current values of the counters.
+func Snapshot() Counters {
+	counters.Lock()
+	panics := make(map[string]uint64, len(counters.panicsByPage))
+	for page, n := range counters.panicsByPage {
+		panics[page] = n
+	}
+	counters.Unlock()
+	return Counters{
+		PanicsByPage: panics,
+		LogOutputs:   logging.AsyncStats(),
+	}
+}
+
+// Handler serves the Snapshot as JSON.  It does no access control of its
+// own, so should only be mounted behind some.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(Snapshot())
+	})
+}
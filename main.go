@@ -18,17 +18,17 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"io"
 	"net"
 	"net/http"
 	"os"
-	"sort"
+	"os/signal"
 	"strings"
-	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/felixge/httpsnoop"
 
+	"go.pennock.tech/dummyapp/internal/accesslog"
 	"go.pennock.tech/dummyapp/internal/logging"
 	"go.pennock.tech/dummyapp/internal/stats"
 	"go.pennock.tech/dummyapp/internal/version"
@@ -37,22 +37,49 @@ import (
 const defaultPortSpec = ":8080"
 
 var options struct {
-	portspec    string
-	showVersion bool
+	portspec        string
+	showVersion     bool
+	shutdownTimeout time.Duration
 }
 
 func init() {
 	flag.StringVar(&options.portspec, "port", defaultPortSpec, "port to listen on for HTTP requests")
 	flag.BoolVar(&options.showVersion, "version", false, "show version and exit")
+	flag.DurationVar(&options.shutdownTimeout, "shutdown.timeout", 10*time.Second, "on SIGTERM/SIGINT, time to finish requests, and then to flush logs")
+}
+
+// A pageCategory groups pages in the index.
+type pageCategory string
+
+const (
+	categoryDiagnostics pageCategory = "diagnostics"
+	categoryContent     pageCategory = "content"
+	categoryOps         pageCategory = "ops"
+	categoryOther       pageCategory = "other"
+)
+
+// pageCategoryOrder is the order in which categories appear in the index;
+// anything unrecognized is treated as categoryOther.
+var pageCategoryOrder = []pageCategory{categoryDiagnostics, categoryContent, categoryOps, categoryOther}
+
+// pageMeta is the human-facing metadata for a page, used in the index and in
+// the OpenAPI document.
+type pageMeta struct {
+	description string
+	category    pageCategory
+	weight      int    // lower sorts earlier within a category, then by name
+	icon        string // optional; a short glyph such as an emoji
 }
 
 type dummyAppFirstLevelPage struct {
+	pageMeta
 	name         string
 	function     http.HandlerFunc
 	handler      http.Handler
 	skipIndex    bool
 	skipRegister bool
-	onlyExistIf  func(logger logging.Logger) bool
+	onlyExistIf  *pageCondition
+	api          *pageAPI
 }
 
 var firstLevelPages map[string]dummyAppFirstLevelPage
@@ -67,10 +94,20 @@ func commonAddFirstLevelPage(name string) {
 	}
 }
 
+// firstLevelPageTaken is for pages configured at runtime, which should be
+// skipped rather than panic when their name is already in use.  A page with
+// a trailing slash takes the name without, and vice versa.
+func firstLevelPageTaken(name string) bool {
+	base := strings.TrimSuffix(name, "/")
+	_, bare := firstLevelPages[base]
+	_, tree := firstLevelPages[base+"/"]
+	return bare || tree
+}
+
 // can have a Handler variant too, I'm just dealing only in Funcs for this dummy app
-func addFirstLevelPageFunc(name string, f http.HandlerFunc) {
+func addFirstLevelPageFunc(name string, f http.HandlerFunc, meta pageMeta) {
 	commonAddFirstLevelPage(name)
-	firstLevelPages[name] = dummyAppFirstLevelPage{name: name, function: f}
+	firstLevelPages[name] = dummyAppFirstLevelPage{pageMeta: meta, name: name, function: f}
 }
 
 func addUnindexedFirstLevelPageFunc(name string, f http.HandlerFunc) {
@@ -87,12 +124,14 @@ type dummyappReqContextKey int
 
 const (
 	dummyappLoggerKey dummyappReqContextKey = iota
+	dummyappRequestStateKey
+	dummyappBasePathKey
+	dummyappSiteKey
 )
 
-// note that zerolog also has its own facility for registering with the context
-// and TODO we should consider how to integrate that too, for other
-// zerolog-using libs, without requiring extra functionality of other libs.
-// Probably a new interface method with a default implementation?
+// Note that zerolog also has its own facility for registering with the
+// context; LogWrapHandler uses AttachToContext to populate that too, so that
+// zerolog-using libs see our per-request fields via zerolog.Ctx().
 func loggerFromContext(ctx context.Context) logging.Logger {
 	l := ctx.Value(dummyappLoggerKey)
 	if l == nil {
@@ -101,72 +140,106 @@ func loggerFromContext(ctx context.Context) logging.Logger {
 	return l.(logging.Logger)
 }
 
-var lastRequestID uint64 // atomic bump; note this is not great for clustered operations
+// requestState is the per-request mutable state shared between LogWrapHandler
+// and the handlers which it wraps.  It's only ever touched from the go-routine
+// serving the request, so needs no locking.
+type requestState struct {
+	id         string
+	errorClass string
+	// path is the URL path as the client sees it, before any prefix
+	// stripping.
+	path  string
+	trace *traceContext
+}
+
+// requestStateFromContext returns nil if there's no request state, which
+// happens when running without logging.
+func requestStateFromContext(ctx context.Context) *requestState {
+	rs, _ := ctx.Value(dummyappRequestStateKey).(*requestState)
+	return rs
+}
 
 // LogWrapHandler adds logging to received HTTP requests, logging before and after the
-// handling and providing the logger in the context to requests.
+// handling and providing the logger in the context to requests.  It also assigns
+// the request ID, see requestid.go, and echoes it in the response headers, and
+// starts the trace span, see tracecontext.go.
 func LogWrapHandler(h http.Handler, logger logging.Logger, name string) http.HandlerFunc {
+	sampler := newRequestSampler()
 	return func(w http.ResponseWriter, req *http.Request) {
-		requestID := atomic.AddUint64(&lastRequestID, 1)
-		logger = logger.WithField("request", requestID).WithField("page", name)
-		logger.
-			WithField("method", req.Method).
-			WithField("url_path", req.URL.Path).
-			WithField("url_query", req.URL.RawQuery).
-			WithField("host", req.Host).
-			WithField("remote", req.RemoteAddr).
-			Info("received") // can decorate with body size, etc etc
-		defer func() {
-			if x := recover(); x != nil {
-				logger.WithField("panic", x).Error("run-time panic")
-			}
-		}()
-		req = req.WithContext(context.WithValue(req.Context(), dummyappLoggerKey, logger))
+		state := &requestState{
+			id:    requestIDFor(req),
+			path:  basePathFromContext(req.Context()) + req.URL.Path,
+			trace: traceContextFor(req),
+		}
+		w.Header().Set(requestIDHeader, state.id)
+		rlog := logger.Fields(
+			logging.Str("request", state.id),
+			logging.Str("page", name),
+			logging.Str("trace_id", state.trace.traceID),
+			logging.Str("span_id", state.trace.spanID))
+		if state.trace.parentID != "" {
+			rlog = rlog.Str("parent_span_id", state.trace.parentID)
+		}
+		withRequest := func(l logging.Logger) logging.Logger {
+			return l.Fields(
+				logging.Str("method", req.Method),
+				logging.Str("url_path", req.URL.Path),
+				logging.Str("url_query", logging.RedactQuery(req.URL.RawQuery)),
+				logging.Str("host", req.Host),
+				logging.Str("remote", req.RemoteAddr))
+		}
+		// The lines for the request itself are sampled rather than rate
+		// limited, so that errors and slow requests are always logged.
+		reqLog := logging.Unlimited(rlog)
+		if sampler == nil {
+			withRequest(reqLog).Info("received") // can decorate with body size, etc etc
+		}
+		ctx := context.WithValue(req.Context(), dummyappLoggerKey, rlog)
+		ctx = context.WithValue(ctx, dummyappRequestStateKey, state)
+		ctx = rlog.AttachToContext(ctx)
+		req = req.WithContext(ctx)
+		// Panics are handled by RecoverWrapHandler, inside us, so that we
+		// still get to log the response which it sends.
+		start := time.Now()
 		m := httpsnoop.CaptureMetrics(h, w, req)
-		// Writing non-JSON logs, `m.Duration` is string-formatted so we get
-		// a pretty value with a suffix, probably µs.  With JSON, we just get
-		// the integer value, which is in ns, and is not obviously so.
-		// Coerce to get a string-of-floating-point.
-		dur := fmt.Sprintf("%.2f", float64(m.Duration)/float64(time.Microsecond))
-		logger.WithField("code", m.Code).WithField("duration_us", dur).WithField("length", m.Written).Info("responded")
+		if accesslog.Enabled() {
+			accesslog.Log(&accesslog.Entry{
+				Start:      start,
+				RemoteAddr: req.RemoteAddr,
+				Method:     req.Method,
+				RequestURI: logging.RedactRequestURI(req.RequestURI),
+				Proto:      req.Proto,
+				Status:     m.Code,
+				Bytes:      m.Written,
+				Duration:   m.Duration,
+				Referer:    logging.RedactURLString(req.Referer()),
+				UserAgent:  req.UserAgent(),
+			})
+		}
+		done := reqLog.Fields(
+			logging.Int("code", int64(m.Code)),
+			logging.Dur("duration_us", m.Duration),
+			logging.Int("length", m.Written))
+		if state.errorClass != "" {
+			done = done.Str("error_class", state.errorClass)
+		}
+		if sampler != nil {
+			ok, rate := sampler.sample(m.Code, state.errorClass, m.Duration)
+			if !ok {
+				return
+			}
+			done = withRequest(done).Int("sample_rate", int64(rate))
+		}
+		done.Info("responded")
 	}
 }
 
 func send404(w http.ResponseWriter, req *http.Request) {
-	w.WriteHeader(http.StatusNotFound)
-	w.Header().Set("Content-Type", "text/plain")
-	io.WriteString(w, "page not found")
+	sendProblem(w, req, http.StatusNotFound, errorClassNotFound, "page not found")
 	l := loggerFromContext(req.Context())
 	if !l.IsDisabled() {
-		l.WithField("http_error", 404).WithField("URL", req.URL).Info("sent 404")
-	}
-}
-
-func rootHandle(w http.ResponseWriter, req *http.Request) {
-	// All paths for valid sub-trees must have been explicitly registered
-	if req.URL.Path != "/" {
-		send404(w, req)
-		return
-	}
-
-	pageNames := make([]string, 0, len(firstLevelPages))
-	for k := range firstLevelPages {
-		if firstLevelPages[k].skipIndex {
-			continue
-		}
-		if firstLevelPages[k].onlyExistIf != nil && !firstLevelPages[k].onlyExistIf(nil) {
-			continue
-		}
-		n := strings.Replace(strings.TrimRight(firstLevelPages[k].name, "/"), "/", " ", -1)
-		pageNames = append(pageNames, n)
-	}
-	sort.Strings(pageNames)
-
-	io.WriteString(w, "<html><head><title>Dummy App</title></head><body><h1>Dummy App</h1>\n<ul>\n")
-	for _, n := range pageNames {
-		fmt.Fprintf(w, " <li><a href=\"%s\">%s</a></li>\n", n, n)
+		l.Int("http_error", 404).Str("URL", logging.RedactURL(req.URL)).Info("sent 404")
 	}
-	io.WriteString(w, "</ul>\n</body></html>\n")
 }
 
 func init() { addUnindexedFirstLevelPageFunc("favicon.ico", send404) }
@@ -185,17 +258,44 @@ func parseFlagsSanely() {
 	}
 }
 
-func registerHandlersOnDefault(logger logging.Logger) {
-	for i := range firstLevelPages {
-		if firstLevelPages[i].skipRegister {
-			continue
-		}
-		if firstLevelPages[i].onlyExistIf != nil && !firstLevelPages[i].onlyExistIf(logger) {
+// A site is a set of pages served together, under one index.  Without
+// virtual hosting, there's just the one site, holding every page.
+type site struct {
+	name  string // the hostname; empty for the default site
+	title string
+	pages map[string]dummyAppFirstLevelPage
+}
+
+const defaultSiteTitle = "Dummy App"
+
+// siteFromContext returns the site serving the request, or nil outside of a
+// site's handlers.
+func siteFromContext(ctx context.Context) *site {
+	s, _ := ctx.Value(dummyappSiteKey).(*site)
+	return s
+}
+
+func newDefaultSite() *site {
+	return &site{title: defaultSiteTitle, pages: firstLevelPages}
+}
+
+// handler returns the handler for all pages of the site.
+func (s *site) handler(logger logging.Logger) http.Handler {
+	mux := s.buildMux(logger)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mux.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), dummyappSiteKey, s)))
+	})
+}
+
+func (s *site) buildMux(logger logging.Logger) *http.ServeMux {
+	mux := http.NewServeMux()
+	for i := range s.pages {
+		if s.pages[i].skipRegister {
 			continue
 		}
-		n := firstLevelPages[i].name
-		f := firstLevelPages[i].function
-		h := firstLevelPages[i].handler
+		n := s.pages[i].name
+		f := s.pages[i].function
+		h := s.pages[i].handler
 		if f != nil && h != nil {
 			panic("given both function and handler for http setup of " + n)
 		}
@@ -205,25 +305,35 @@ func registerHandlersOnDefault(logger logging.Logger) {
 		if h == nil {
 			h = http.HandlerFunc(f)
 		}
-		// missing the IsDisabled is harmless aside from some extra cycles on each call
-		if !logger.IsDisabled() {
+		if cond := s.pages[i].onlyExistIf; cond != nil {
+			h = conditionalHandler(cond, h)
+		}
+		h = RecoverWrapHandler(h, n)
+		// missing the IsDisabled is harmless aside from some extra cycles on
+		// each call; we still need the wrapper for the access log.
+		if !logger.IsDisabled() || accesslog.Enabled() {
 			h = LogWrapHandler(h, logger, n)
 			logger.WithField("page", "/"+n).Debug("registering page handler")
 		}
-		http.Handle("/"+n, h)
+		mux.Handle("/"+n, h)
 	}
-	h := http.HandlerFunc(rootHandle)
-	if !logger.IsDisabled() {
+	h := RecoverWrapHandler(http.HandlerFunc(s.rootHandle), "/")
+	if !logger.IsDisabled() || accesslog.Enabled() {
 		h = LogWrapHandler(h, logger, "/")
 	}
-	http.Handle("/", h)
+	mux.Handle("/", h)
+	return mux
 }
 
 func setupWebserver(logger logging.Logger) func() error {
-	registerHandlersOnDefault(logger)
+	// Our own mux, not the DefaultServeMux, so that only what we register
+	// here is served, not whatever an imported package registers there.
+	mux := http.NewServeMux()
+	mux.Handle("/", basePathHandler(setupSites(logger), logger))
 
 	server := &http.Server{
-		Addr: options.portspec,
+		Addr:    options.portspec,
+		Handler: mux,
 	}
 	listener, err := net.Listen("tcp", server.Addr)
 	if err != nil {
@@ -238,12 +348,35 @@ func setupWebserver(logger logging.Logger) func() error {
 		return nil
 	}
 
+	// On SIGTERM or SIGINT we stop accepting connections and let in-flight
+	// requests finish, so that Serve returns and realMain can flush the logs.
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT)
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		sig := <-sigs
+		signal.Stop(sigs)
+		logger.WithField("signal", sig.String()).Info("shutting down")
+		ctx, cancel := context.WithTimeout(context.Background(), options.shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			logger.WithError(err).Warning("requests still in progress at shutdown")
+			server.Close()
+		}
+	}()
+
 	return func() error {
 		logger.
 			WithField("listen", server.Addr).
 			WithField("bound", listener.Addr().String()).
 			Info("accepting connections")
-		return server.Serve(listener)
+		if err := server.Serve(listener); err != http.ErrServerClosed {
+			return err
+		}
+		// Serve returns as soon as Shutdown starts, not once it's done.
+		<-drained
+		return nil
 	}
 }
 
@@ -257,6 +390,13 @@ func realMain() int {
 	}
 
 	logger := logging.Setup()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), options.shutdownTimeout)
+		defer cancel()
+		if err := logging.Shutdown(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+		}
+	}()
 	masterThreadLogger := logger.
 		WithField("uid", os.Getuid()).
 		WithField("gid", os.Getgid()).
@@ -268,6 +408,20 @@ func realMain() int {
 	}
 	startupLogCtx.Info("starting")
 
+	setupRequestIDs()
+	if err := accesslog.Setup(); err != nil {
+		logger.WithError(err).Error("unable to open access log")
+		return 1
+	}
+	if accesslog.Enabled() {
+		file, format := accesslog.Description()
+		logger.WithField("file", file).WithField("format", format).Info("writing access log")
+	}
+	if err := setupAdmin(logger); err != nil {
+		logger.WithError(err).Error("unable to set up admin endpoints")
+		return 1
+	}
+
 	statsManager, err := stats.Start(logger.WithField("component", "stats"))
 	if err != nil {
 		logger.WithError(err).Error("failed to start stats manager")
@@ -276,6 +430,8 @@ func realMain() int {
 	}
 
 	_ = setupPoetry(logger) // we don't care if it succeeds or not, let it log
+	_ = setupStaticMounts(logger)
+	_ = setupProxies(logger)
 
 	serve := setupWebserver(logger)
 	if serve == nil {
@@ -284,9 +440,10 @@ func realMain() int {
 
 	demonstrateStdlibLogger()
 
-	// Maybe show using waitgroups and shutdown channels, but that's not really
-	// the point of this demo and I've already done too much on this side,
-	// complicating things.
+	// Shutdown is just the web server being told to stop, on a signal, and
+	// the deferred log flush above.  Maybe show using waitgroups and shutdown
+	// channels for more, but that's not really the point of this demo and
+	// I've already done too much on this side, complicating things.
 	//
 	// Note that we allow the stupidity of running without logging, and short-circuit
 	// that above for convenience.  So rework that if expanding this.
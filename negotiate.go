@@ -0,0 +1,147 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// A responseFormat is one of the representations which a handler is able to
+// produce; handlers offer a list of these, in order of their own preference,
+// and negotiateFormat picks one based upon what the client asked for.
+type responseFormat int
+
+const (
+	formatHTML responseFormat = iota
+	formatJSON
+	formatText
+	// formatProblem is RFC 9457 problem details, only offered by sendProblem
+	// so that clients asking for it specifically get it.
+	formatProblem
+)
+
+const problemMediaType = "application/problem+json"
+
+// formatQueryParam is the query parameter which lets a human (or a lazy
+// curl invocation) override the Accept header.
+const formatQueryParam = "format"
+
+func (f responseFormat) mediaType() string {
+	switch f {
+	case formatJSON:
+		return "application/json"
+	case formatText:
+		return "text/plain"
+	case formatProblem:
+		return problemMediaType
+	default:
+		return "text/html"
+	}
+}
+
+// contentType is the full Content-Type header value for the format, with
+// charset where that is meaningful.
+func (f responseFormat) contentType() string {
+	switch f {
+	case formatJSON, formatProblem:
+		return f.mediaType()
+	default:
+		return f.mediaType() + "; charset=utf-8"
+	}
+}
+
+func formatFromName(name string) (responseFormat, bool) {
+	switch strings.ToLower(name) {
+	case "html", "htm":
+		return formatHTML, true
+	case "json":
+		return formatJSON, true
+	case "text", "txt", "plain":
+		return formatText, true
+	}
+	return 0, false
+}
+
+// negotiateFormat returns the format to use for the response.  An explicit
+// `?format=` query parameter naming an offered format always wins; otherwise
+// we honour the Accept header, including q-values.  Ties go to whichever
+// the handler listed first.  If the client will accept none of our offers
+// then we use our first preference anyway: this is a diagnostics app, and a
+// response in the "wrong" format is more useful than a 406.
+func negotiateFormat(req *http.Request, offers ...responseFormat) responseFormat {
+	if len(offers) == 0 {
+		return formatHTML
+	}
+	if name := req.URL.Query().Get(formatQueryParam); name != "" {
+		if f, ok := formatFromName(name); ok {
+			for _, o := range offers {
+				if o == f {
+					return f
+				}
+			}
+		}
+	}
+
+	accept := req.Header.Get("Accept")
+	if accept == "" {
+		return offers[0]
+	}
+
+	best := offers[0]
+	bestQ := -1.0
+	for _, o := range offers {
+		if q := acceptQuality(accept, o.mediaType()); q > bestQ {
+			best, bestQ = o, q
+		}
+	}
+	if bestQ <= 0 {
+		return offers[0]
+	}
+	return best
+}
+
+// acceptQuality returns the q-value which the Accept header value gives to
+// the media type, using the most specific matching range.  It returns 0 when
+// nothing matches.
+func acceptQuality(accept, mediaType string) float64 {
+	major := mediaType
+	if i := strings.IndexByte(mediaType, '/'); i >= 0 {
+		major = mediaType[:i]
+	}
+
+	quality := 0.0
+	specificity := -1
+	for _, rangeSpec := range strings.Split(accept, ",") {
+		rangeType, params, err := mime.ParseMediaType(strings.TrimSpace(rangeSpec))
+		if err != nil {
+			continue
+		}
+		var spec int
+		switch {
+		case rangeType == mediaType:
+			spec = 2
+		case rangeType == major+"/*":
+			spec = 1
+		case rangeType == "*/*":
+			spec = 0
+		default:
+			continue
+		}
+		if spec < specificity {
+			continue
+		}
+		q := 1.0
+		if qs, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(qs, 64); err == nil {
+				q = parsed
+			}
+		}
+		specificity, quality = spec, q
+	}
+	return quality
+}
@@ -0,0 +1,79 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	all := []responseFormat{formatHTML, formatJSON, formatText}
+	for _, tc := range []struct {
+		name   string
+		target string
+		accept string
+		offers []responseFormat
+		want   responseFormat
+	}{
+		{"no accept", "/", "", all, formatHTML},
+		{"no offers", "/", "application/json", nil, formatHTML},
+		{"exact", "/", "application/json", all, formatJSON},
+		{"exact text", "/", "text/plain", all, formatText},
+		{"browser", "/", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", all, formatHTML},
+		{"q-values", "/", "text/html;q=0.5, application/json;q=0.9", all, formatJSON},
+		{"q-value zero", "/", "application/json;q=0, text/plain", all, formatText},
+		{"major wildcard", "/", "text/*", []responseFormat{formatJSON, formatText}, formatText},
+		{"major wildcard tie", "/", "text/*", all, formatHTML},
+		{"specific beats wildcard", "/", "text/*;q=0.1, text/plain", all, formatText},
+		{"specific lowers wildcard", "/", "*/*, text/html;q=0.2", all, formatJSON},
+		{"full wildcard", "/", "*/*", []responseFormat{formatText, formatJSON}, formatText},
+		{"tie goes to first offer", "/", "application/json, text/plain", []responseFormat{formatText, formatJSON}, formatText},
+		{"nothing acceptable", "/", "image/png", []responseFormat{formatJSON, formatText}, formatJSON},
+		{"all refused", "/", "*/*;q=0", all, formatHTML},
+		{"malformed range skipped", "/", "garbage;;, application/json", all, formatJSON},
+		{"bad q taken as 1", "/", "text/plain;q=high, application/json;q=0.5", all, formatText},
+		{"case insensitive type", "/", "Application/JSON", all, formatJSON},
+		{"query override", "/?format=json", "text/html", all, formatJSON},
+		{"query override alias", "/?format=TXT", "application/json", all, formatText},
+		{"query override not offered", "/?format=text", "application/json", []responseFormat{formatHTML, formatJSON}, formatJSON},
+		{"query override unknown", "/?format=xml", "text/plain", all, formatText},
+		{"problem only when offered", "/", "application/problem+json", []responseFormat{formatProblem, formatJSON}, formatProblem},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.target, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			if got := negotiateFormat(req, tc.offers...); got != tc.want {
+				t.Errorf("negotiateFormat(Accept %q) = %v, want %v", tc.accept, got.mediaType(), tc.want.mediaType())
+			}
+		})
+	}
+}
+
+func TestAcceptQuality(t *testing.T) {
+	for _, tc := range []struct {
+		accept    string
+		mediaType string
+		want      float64
+	}{
+		{"text/html", "text/html", 1},
+		{"text/html;q=0.3", "text/html", 0.3},
+		{"text/html; q=0.3; level=1", "text/html", 0.3},
+		{"text/*;q=0.4", "text/plain", 0.4},
+		{"*/*;q=0.1", "application/json", 0.1},
+		{"*/*;q=0.1, text/*;q=0.2, text/plain;q=0.3", "text/plain", 0.3},
+		{"text/plain;q=0.3, text/*;q=0.2, */*;q=0.1", "text/plain", 0.3},
+		{"text/plain;q=0.3, text/*;q=0.2", "text/html", 0.2},
+		{"application/json", "text/plain", 0},
+		{"", "text/plain", 0},
+		{"text/plain;q=bogus", "text/plain", 1},
+	} {
+		if got := acceptQuality(tc.accept, tc.mediaType); got != tc.want {
+			t.Errorf("acceptQuality(%q, %q) = %v, want %v", tc.accept, tc.mediaType, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.pennock.tech/dummyapp/internal/version"
+)
+
+// The OpenAPI document is generated from the pages of the site serving the
+// request, using the api metadata on each page, so that it can't drift from
+// what we actually serve.  Pages without api metadata are described as a
+// plain GET returning HTML, unless they're unindexed, in which case they're
+// assumed to be plumbing and left out.
+
+// pageAPI is the OpenAPI metadata for a page.
+type pageAPI struct {
+	methods      []string // default: GET
+	contentTypes []string // of successful responses; default: text/html
+	params       []pageAPIParam
+}
+
+type pageAPIParam struct {
+	name        string
+	in          string // "query" or "header"
+	description string
+	enum        []string
+}
+
+var defaultPageAPI = pageAPI{
+	methods:      []string{http.MethodGet},
+	contentTypes: []string{"text/html"},
+}
+
+// formatParam is the API description of the parameter handled by
+// negotiateFormat.
+var formatParam = pageAPIParam{
+	name:        formatQueryParam,
+	in:          "query",
+	description: "response format, overriding the Accept header",
+	enum:        []string{"html", "json", "text"},
+}
+
+// The types below are the subset of OpenAPI 3.0 which we need.
+
+type openAPIDocument struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       openAPIInfo                `json:"info"`
+	Servers    []openAPIServer            `json:"servers,omitempty"`
+	Paths      map[string]openAPIPathItem `json:"paths"`
+	Components openAPIComponents          `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIServer struct {
+	URL string `json:"url"`
+}
+
+type openAPIPathItem map[string]*openAPIOperation
+
+type openAPIOperation struct {
+	Summary     string                     `json:"summary,omitempty"`
+	OperationID string                     `json:"operationId,omitempty"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string        `json:"name"`
+	In          string        `json:"in"`
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required,omitempty"`
+	Schema      openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref        string                   `json:"$ref,omitempty"`
+	Type       string                   `json:"type,omitempty"`
+	Enum       []string                 `json:"enum,omitempty"`
+	Properties map[string]openAPISchema `json:"properties,omitempty"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema,omitempty"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]openAPISchema `json:"schemas"`
+}
+
+var problemSchema = openAPISchema{
+	Type: "object",
+	Properties: map[string]openAPISchema{
+		"type":        {Type: "string"},
+		"title":       {Type: "string"},
+		"status":      {Type: "integer"},
+		"detail":      {Type: "string"},
+		"instance":    {Type: "string"},
+		"request_id":  {Type: "string"},
+		"incident_id": {Type: "string"},
+	},
+}
+
+func openAPIOperationFor(opID, summary string, api *pageAPI, subtree bool) *openAPIOperation {
+	content := make(map[string]openAPIMediaType, len(api.contentTypes))
+	for _, ct := range api.contentTypes {
+		content[ct] = openAPIMediaType{}
+	}
+	op := &openAPIOperation{
+		Summary:     summary,
+		OperationID: opID,
+		Responses: map[string]openAPIResponse{
+			"200": {Description: "success", Content: content},
+			"default": {
+				Description: "error",
+				Content: map[string]openAPIMediaType{
+					problemMediaType: {Schema: &openAPISchema{Ref: "#/components/schemas/Problem"}},
+					"text/html":      {},
+				},
+			},
+		},
+	}
+	if subtree {
+		op.Parameters = append(op.Parameters, openAPIParameter{
+			Name:     "path",
+			In:       "path",
+			Required: true,
+			Schema:   openAPISchema{Type: "string"},
+		})
+	}
+	for _, p := range api.params {
+		op.Parameters = append(op.Parameters, openAPIParameter{
+			Name:        p.name,
+			In:          p.in,
+			Description: p.description,
+			Schema:      openAPISchema{Type: "string", Enum: p.enum},
+		})
+	}
+	return op
+}
+
+func operationID(method, name string) string {
+	id := strings.Trim(name, "/")
+	if id == "" {
+		id = "index"
+	}
+	id = strings.NewReplacer("/", "_", ".", "_", "-", "_").Replace(id)
+	return strings.ToLower(method) + "_" + id
+}
+
+// openAPI builds the document for the site, as it currently stands.
+func (s *site) openAPI(req *http.Request) *openAPIDocument {
+	doc := &openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    openAPIInfo{Title: s.title, Version: version.CurrentVersion()},
+		Paths:   make(map[string]openAPIPathItem, len(s.pages)+1),
+		Components: openAPIComponents{
+			Schemas: map[string]openAPISchema{"Problem": problemSchema},
+		},
+	}
+	if base := basePathFromContext(req.Context()); base != "" {
+		doc.Servers = []openAPIServer{{URL: base}}
+	}
+
+	indexAPI := &pageAPI{
+		methods:      []string{http.MethodGet},
+		contentTypes: []string{"text/html", "application/json", "text/plain"},
+		params:       []pageAPIParam{formatParam},
+	}
+	doc.Paths["/"] = openAPIPathItem{"get": openAPIOperationFor("get_index", "Index of pages", indexAPI, false)}
+
+	names := make([]string, 0, len(s.pages))
+	for k := range s.pages {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		page := s.pages[k]
+		if page.skipRegister || (page.skipIndex && page.api == nil) {
+			continue
+		}
+		if !page.onlyExistIf.exists(req.Context()) {
+			continue
+		}
+		api := page.api
+		if api == nil {
+			api = &defaultPageAPI
+		}
+		subtree := strings.HasSuffix(page.name, "/")
+		p := "/" + page.name
+		if subtree {
+			p += "{path}"
+		}
+		item := make(openAPIPathItem, len(api.methods))
+		for _, m := range api.methods {
+			item[strings.ToLower(m)] = openAPIOperationFor(operationID(m, page.name), page.description, api, subtree)
+		}
+		doc.Paths[p] = item
+	}
+	return doc
+}
+
+func openAPIHandle(w http.ResponseWriter, req *http.Request) {
+	s := siteFromContext(req.Context())
+	if s == nil {
+		sendProblem(w, req, http.StatusInternalServerError, errorClassInternal, "no site for request")
+		return
+	}
+	w.Header().Set("Content-Type", formatJSON.contentType())
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s.openAPI(req)); err != nil {
+		loggerFromContext(req.Context()).WithError(err).Warning("failed writing OpenAPI document")
+	}
+}
+
+// openAPIViewerHTML is a small self-contained page which fetches the OpenAPI
+// document, relative to itself, and renders it; we don't want to pull in a
+// whole JS framework for a dummy app.
+//
+//go:embed openapi-viewer.html
+var openAPIViewerHTML []byte
+
+func openAPIViewerHandle(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", formatHTML.contentType())
+	w.Write(openAPIViewerHTML)
+}
+
+func init() {
+	addFirstLevelPageItem(dummyAppFirstLevelPage{
+		pageMeta: pageMeta{
+			description: "OpenAPI description of this site",
+			category:    categoryOps,
+		},
+		name:      "openapi.json",
+		function:  openAPIHandle,
+		skipIndex: true,
+		api: &pageAPI{
+			methods:      []string{http.MethodGet},
+			contentTypes: []string{"application/json"},
+		},
+	})
+	addFirstLevelPageFunc("openapi", openAPIViewerHandle, pageMeta{
+		description: "API documentation viewer",
+		category:    categoryOps,
+		weight:      10,
+		icon:        "📖",
+	})
+}
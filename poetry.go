@@ -10,8 +10,6 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"strings"
-	"syscall"
 
 	"go.pennock.tech/dummyapp/internal/logging"
 )
@@ -55,26 +53,10 @@ func init() {
 	flag.StringVar(&poetryOptions.dir, "poetry.dir", defaultDir, "poetry serving directory"+suffix)
 }
 
+// poetryDir is the static mount file-system, plus the oops hack.
 type poetryDir string
 
 func (dir poetryDir) Open(name string) (http.File, error) {
-	// We can't use loggerFromContext because we don't get the req,
-	// only a literal filename.
-	//
-	// We register with StripPrefix, so that a leading /poem is removed; after which,
-	// we're called with `/` for the root page, and if we return a directory,
-	// then called again with `/index.html`.
-	// Note that ${poetry}/ might contain sub-directories with dot files.
-	// Thus rather than look for a leading dot, we just look for /. to prohibit.
-	//
-	// However, the indexing is not solved.  I can't be bothered to figure out
-	// correct portable (across path-sep variances) changes in safely joining
-	// the path to the FS root, replicating the unexported functionality of the
-	// stdlib, and this was supposed to be a small demo.  So for now, rather
-	// than just handle correctness at the top-level and not for sub-dirs,
-	// we'll just blindly accept that we're returning plainly formatted lists
-	// which contains entries which might be rejected.
-
 	if oops_REGISTER_POEM_POETRY {
 		// We return our own index, at /poetry/, to handle stripping out leading-dots.
 		// So error if someone tries to access the FS root.
@@ -82,10 +64,7 @@ func (dir poetryDir) Open(name string) (http.File, error) {
 			return nil, ErrNoPoemGiven
 		}
 	}
-	if strings.Contains(name, "/.") {
-		return nil, os.ErrPermission
-	}
-	return http.Dir(dir).Open(name)
+	return staticDir{root: string(dir), listing: true, indexFile: defaultStaticIndex}.Open(name)
 }
 
 func poetryHandleFunc(w http.ResponseWriter, req *http.Request) {
@@ -106,30 +85,48 @@ func setupPoetry(logger logging.Logger) bool {
 }
 
 func setupPoetryNolog() error {
-	fi, err := os.Stat(poetryOptions.dir)
-	if err != nil {
+	if err := checkDir(poetryOptions.dir); err != nil {
 		return err
 	}
-	if !fi.IsDir() {
-		return syscall.ENOTDIR
+	for _, page := range poetryPages(poetryOptions.dir) {
+		addFirstLevelPageItem(page)
 	}
+	return nil
+}
 
+// poetryPages returns the pages for serving poetry from the directory; it's
+// separate from the registration so that virtual hosts can use their own
+// directories.
+func poetryPages(dir string) []dummyAppFirstLevelPage {
 	if oops_REGISTER_POEM_POETRY {
-		addFirstLevelPageItem(dummyAppFirstLevelPage{
-			name:      "poem/",
-			handler:   http.StripPrefix("/poem", http.FileServer(poetryDir(poetryOptions.dir))),
-			skipIndex: true,
-		})
-
-		addFirstLevelPageItem(dummyAppFirstLevelPage{
-			name:     "poetry",
-			function: poetryHandleFunc,
-		})
-	} else {
-		addFirstLevelPageItem(dummyAppFirstLevelPage{
-			name:    "poetry/",
-			handler: http.StripPrefix("/poetry", http.FileServer(poetryDir(poetryOptions.dir))),
-		})
+		return []dummyAppFirstLevelPage{
+			{
+				name:        "poem/",
+				handler:     interceptProblems(http.StripPrefix("/poem", http.FileServer(poetryDir(dir)))),
+				skipIndex:   true,
+				onlyExistIf: conditionDirExists(dir),
+			},
+			{
+				pageMeta: pageMeta{
+					description: "Some poems to read",
+					category:    categoryContent,
+					icon:        "📜",
+				},
+				name:     "poetry",
+				function: poetryHandleFunc,
+			},
+		}
 	}
-	return nil
+	m := &staticMount{
+		name:      "poetry",
+		dir:       dir,
+		listing:   true,
+		indexFile: defaultStaticIndex,
+		meta: pageMeta{
+			description: "Some poems to read",
+			category:    categoryContent,
+			icon:        "📜",
+		},
+	}
+	return []dummyAppFirstLevelPage{m.page()}
 }
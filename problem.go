@@ -0,0 +1,201 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/felixge/httpsnoop"
+)
+
+// All error responses should go through sendProblem, so that clients get
+// consistent bodies and our "responded" log line gets an error_class.  The
+// JSON form is RFC 9457 problem details; we don't define problem type URIs,
+// so per the RFC the type is implicitly "about:blank" and the title is just
+// the HTTP status text.
+
+// The error classes are short stable tokens for the logs; keep them
+// lower-case with underscores, and don't put anything variable in them,
+// that belongs in the detail.
+const (
+	errorClassNotFound         = "not_found"
+	errorClassForbidden        = "forbidden"
+	errorClassUnauthorized     = "unauthorized"
+	errorClassBadRequest       = "bad_request"
+	errorClassMethod           = "method"
+	errorClassMisdirected      = "misdirected"
+	errorClassInternal         = "internal"
+	errorClassPanic            = "panic"
+	errorClassUpstream         = "upstream"
+	errorClassPartial          = "partial"
+	errorClassFromInnerHandler = "handler"
+)
+
+type problemDetails struct {
+	Type      string `json:"type,omitempty"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	Incident  string `json:"incident_id,omitempty"`
+}
+
+var problemHTMLTemplate = template.Must(template.New("problem").Parse(`<html><head><title>{{.Status}} {{.Title}}</title></head><body><h1 class="error">{{.Status}} {{.Title}}</h1>
+{{- with .Detail}}
+<div class="error errmsg">{{.}}</div>
+{{- end}}
+{{- with .RequestID}}
+<p>Request ID: <code>{{.}}</code></p>
+{{- end}}
+{{- with .Incident}}
+<p>Incident ID: <code>{{.}}</code></p>
+{{- end}}
+</body></html>
+`))
+
+// noteErrorClass records the error class for the "responded" log line,
+// without otherwise affecting the response; it's for handlers which render a
+// partial failure inline and still return a page.  The first class recorded
+// wins.
+func noteErrorClass(req *http.Request, class string) {
+	if state := requestStateFromContext(req.Context()); state != nil && state.errorClass == "" {
+		state.errorClass = class
+	}
+}
+
+// sendProblem writes a complete error response for the status code, in
+// whichever format the client prefers.  The detail is shown to the client,
+// so must not contain anything sensitive.
+func sendProblem(w http.ResponseWriter, req *http.Request, status int, class, detail string) {
+	writeProblem(w, req, class, problemDetails{
+		Status: status,
+		Detail: detail,
+	})
+}
+
+// writeProblem is sendProblem for callers which need to set extra members of
+// the problem details; Title and Instance are filled in if empty, RequestID
+// always.
+func writeProblem(w http.ResponseWriter, req *http.Request, class string, p problemDetails) {
+	noteErrorClass(req, class)
+
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	state := requestStateFromContext(req.Context())
+	if state != nil {
+		p.RequestID = state.id
+	}
+	if p.Instance == "" {
+		if state != nil {
+			p.Instance = state.path
+		} else {
+			p.Instance = req.URL.Path
+		}
+	}
+
+	h := w.Header()
+	// Anything set by the handler which failed is suspect.
+	h.Del("Content-Length")
+	h.Del("Content-Encoding")
+	h.Del("Last-Modified")
+	h.Del("ETag")
+	h.Del("Cache-Control")
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Add("Vary", "Accept")
+
+	var err error
+	switch negotiateFormat(req, formatProblem, formatJSON, formatHTML, formatText) {
+	case formatHTML:
+		h.Set("Content-Type", formatHTML.contentType())
+		w.WriteHeader(p.Status)
+		err = problemHTMLTemplate.Execute(w, p)
+	case formatText:
+		h.Set("Content-Type", formatText.contentType())
+		w.WriteHeader(p.Status)
+		if p.Detail != "" {
+			_, err = fmt.Fprintf(w, "%d %s: %s\n", p.Status, p.Title, p.Detail)
+		} else {
+			_, err = fmt.Fprintf(w, "%d %s\n", p.Status, p.Title)
+		}
+	default:
+		h.Set("Content-Type", formatProblem.contentType())
+		w.WriteHeader(p.Status)
+		err = json.NewEncoder(w).Encode(p)
+	}
+	if err != nil {
+		loggerFromContext(req.Context()).WithError(err).Warning("failed writing error response")
+	}
+}
+
+// problemInterceptor is a ResponseWriter wrapper for handlers we don't
+// control, such as http.FileServer, which write their own errors with
+// http.Error.  Any error status is held back and the plain-text body
+// collected, so that we can send a problem response instead.
+type problemInterceptor struct {
+	status    int
+	committed bool
+	body      bytes.Buffer
+}
+
+// interceptProblems wraps a handler so that its error responses go through
+// sendProblem.
+func interceptProblems(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		pi := &problemInterceptor{}
+		ww := httpsnoop.Wrap(w, httpsnoop.Hooks{
+			WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+				return func(code int) {
+					if pi.status != 0 {
+						return
+					}
+					if code >= 400 && !pi.committed {
+						pi.status = code
+						return
+					}
+					pi.committed = true
+					next(code)
+				}
+			},
+			Write: func(next httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+				return func(b []byte) (int, error) {
+					if pi.status != 0 {
+						return pi.body.Write(b)
+					}
+					pi.committed = true
+					return next(b)
+				}
+			},
+			ReadFrom: func(next httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+				return func(src io.Reader) (int64, error) {
+					if pi.status != 0 {
+						return pi.body.ReadFrom(src)
+					}
+					pi.committed = true
+					return next(src)
+				}
+			},
+		})
+		h.ServeHTTP(ww, req)
+		if pi.status == 0 {
+			return
+		}
+		class := errorClassFromInnerHandler
+		switch pi.status {
+		case http.StatusNotFound:
+			class = errorClassNotFound
+		case http.StatusForbidden:
+			class = errorClassForbidden
+		}
+		sendProblem(w, req, pi.status, class, strings.TrimSpace(pi.body.String()))
+	})
+}
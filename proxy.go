@@ -0,0 +1,333 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.pennock.tech/dummyapp/internal/logging"
+)
+
+// Proxy pages front small internal tools: each one is a first-level page
+// which is reverse-proxied to an upstream URL.  Simple ones can be given on
+// the command-line as `-proxy name=URL` (repeatable); anything needing more
+// tuning goes in a JSON file given with `-proxy.config`, which holds an
+// array of proxyConfig objects.
+
+const (
+	defaultProxyTimeout        = 30 * time.Second
+	defaultProxyHealthInterval = 15 * time.Second
+)
+
+var proxyOptions struct {
+	specs      proxySpecList
+	configFile string
+	timeout    time.Duration
+}
+
+func init() {
+	flag.Var(&proxyOptions.specs, "proxy", "`name=URL` to reverse-proxy /name/ to URL (repeatable)")
+	flag.StringVar(&proxyOptions.configFile, "proxy.config", "", "JSON file with an array of proxy page definitions")
+	flag.DurationVar(&proxyOptions.timeout, "proxy.timeout", defaultProxyTimeout, "default timeout for proxied upstreams to respond")
+}
+
+// proxySpecList is a flag.Value accumulating the `-proxy` flags.
+type proxySpecList []proxyConfig
+
+func (l *proxySpecList) String() string {
+	if l == nil {
+		return ""
+	}
+	parts := make([]string, len(*l))
+	for i := range *l {
+		parts[i] = (*l)[i].Name + "=" + (*l)[i].Upstream
+	}
+	return strings.Join(parts, " ")
+}
+
+func (l *proxySpecList) Set(value string) error {
+	name, upstream, ok := strings.Cut(value, "=")
+	if !ok || name == "" || upstream == "" {
+		return fmt.Errorf("proxy spec %q not of form name=URL", value)
+	}
+	*l = append(*l, proxyConfig{Name: name, Upstream: upstream})
+	return nil
+}
+
+// jsonDuration lets durations in the config file be written as "10s".
+type jsonDuration time.Duration
+
+func (d *jsonDuration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = jsonDuration(parsed)
+	return nil
+}
+
+// proxyConfig is one proxied page.  StripPrefix is a pointer so that we can
+// tell "unset" (which defaults to true) from false.
+type proxyConfig struct {
+	Name                  string            `json:"name"`
+	Upstream              string            `json:"upstream"`
+	Description           string            `json:"description"`
+	Category              string            `json:"category"`
+	Weight                int               `json:"weight"`
+	Icon                  string            `json:"icon"`
+	StripPrefix           *bool             `json:"strip_prefix"`
+	Timeout               jsonDuration      `json:"timeout"`
+	SetRequestHeaders     map[string]string `json:"set_request_headers"`
+	RemoveRequestHeaders  []string          `json:"remove_request_headers"`
+	SetResponseHeaders    map[string]string `json:"set_response_headers"`
+	RemoveResponseHeaders []string          `json:"remove_response_headers"`
+	HealthPath            string            `json:"health_path"`
+	HealthInterval        jsonDuration      `json:"health_interval"`
+}
+
+func loadProxyConfigFile(filename string) ([]proxyConfig, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var configs []proxyConfig
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&configs); err != nil {
+		return nil, fmt.Errorf("parsing %q: %w", filename, err)
+	}
+	return configs, nil
+}
+
+// setupProxies should be called by the main go-routine after options have
+// been parsed, before the webserver is set up.  Each bad proxy definition is
+// logged and skipped; it returns how many proxies were registered.
+func setupProxies(logger logging.Logger) int {
+	configs := append([]proxyConfig(nil), proxyOptions.specs...)
+	if proxyOptions.configFile != "" {
+		more, err := loadProxyConfigFile(proxyOptions.configFile)
+		if err != nil {
+			logger.WithError(err).WithField("file", proxyOptions.configFile).Warning("skipping proxy config file")
+		}
+		configs = append(configs, more...)
+	}
+
+	count := 0
+	for i := range configs {
+		pl := logger.WithField("proxy", configs[i].Name).WithField("upstream", logging.RedactURLString(configs[i].Upstream))
+		if firstLevelPageTaken(configs[i].Name) {
+			pl.WithError(errPageNameTaken).Warning("skipping proxy setup")
+			continue
+		}
+		page, err := newProxyPage(&configs[i], pl)
+		if err != nil {
+			pl.WithError(err).Warning("skipping proxy setup")
+			continue
+		}
+		addFirstLevelPageItem(page)
+		pl.Info("proxying page")
+		count++
+	}
+	return count
+}
+
+var (
+	errProxyNameInvalid = errors.New("proxy: name must be a simple path segment")
+	errPageNameTaken    = errors.New("a page with that name already exists")
+)
+
+func newProxyPage(cfg *proxyConfig, logger logging.Logger) (dummyAppFirstLevelPage, error) {
+	if cfg.Name == "" || strings.ContainsAny(cfg.Name, "/?#") || strings.HasPrefix(cfg.Name, ".") {
+		return dummyAppFirstLevelPage{}, errProxyNameInvalid
+	}
+	target, err := url.Parse(cfg.Upstream)
+	if err != nil {
+		return dummyAppFirstLevelPage{}, err
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return dummyAppFirstLevelPage{}, fmt.Errorf("proxy: unsupported upstream scheme %q", target.Scheme)
+	}
+
+	timeout := time.Duration(cfg.Timeout)
+	if timeout <= 0 {
+		timeout = proxyOptions.timeout
+	}
+	strip := cfg.StripPrefix == nil || *cfg.StripPrefix
+	prefix := "/" + cfg.Name
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.ResponseHeaderTimeout = timeout
+
+	up := &upstreamHealth{}
+	up.healthy.Store(true)
+
+	rp := &httputil.ReverseProxy{
+		Transport: &timingTransport{next: transport},
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			if strip {
+				p := strings.TrimPrefix(pr.In.URL.Path, prefix)
+				if !strings.HasPrefix(p, "/") {
+					p = "/" + p
+				}
+				pr.Out.URL.Path = p
+				pr.Out.URL.RawPath = ""
+				pr.Out.Header.Set("X-Forwarded-Prefix", basePathFromContext(pr.In.Context())+prefix)
+			}
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			injectTraceContext(pr.In.Context(), pr.Out.Header)
+			if id := requestIDFromContext(pr.In.Context()); id != "" {
+				pr.Out.Header.Set(requestIDHeader, id)
+			}
+			for _, k := range cfg.RemoveRequestHeaders {
+				pr.Out.Header.Del(k)
+			}
+			for k, v := range cfg.SetRequestHeaders {
+				pr.Out.Header.Set(k, v)
+			}
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			for _, k := range cfg.RemoveResponseHeaders {
+				resp.Header.Del(k)
+			}
+			for k, v := range cfg.SetResponseHeaders {
+				resp.Header.Set(k, v)
+			}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			loggerFromContext(req.Context()).WithError(err).Warning("proxy upstream failed")
+			status := http.StatusBadGateway
+			if errors.Is(err, context.DeadlineExceeded) || isTimeout(err) {
+				status = http.StatusGatewayTimeout
+			}
+			sendProblem(w, req, status, errorClassUpstream, "upstream request failed")
+		},
+	}
+
+	if cfg.HealthPath != "" {
+		interval := time.Duration(cfg.HealthInterval)
+		if interval <= 0 {
+			interval = defaultProxyHealthInterval
+		}
+		healthURL := target.JoinPath(cfg.HealthPath)
+		client := &http.Client{Transport: transport, Timeout: timeout}
+		go up.monitor(client, healthURL.String(), interval, logger)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !up.healthy.Load() {
+			sendProblem(w, req, http.StatusServiceUnavailable, errorClassUpstream, "upstream is failing health checks")
+			return
+		}
+		rp.ServeHTTP(w, req)
+	})
+
+	category := pageCategory(cfg.Category)
+	if category == "" {
+		category = categoryOps
+	}
+	return dummyAppFirstLevelPage{
+		pageMeta: pageMeta{
+			description: cfg.Description,
+			category:    category,
+			weight:      cfg.Weight,
+			icon:        cfg.Icon,
+		},
+		name:    cfg.Name + "/",
+		handler: handler,
+		api: &pageAPI{
+			methods: []string{
+				http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+				http.MethodPatch, http.MethodDelete, http.MethodOptions,
+			},
+			contentTypes: []string{"*/*"},
+		},
+	}, nil
+}
+
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+// timingTransport logs how long the upstream took to give us response
+// headers, separately from the total duration which LogWrapHandler logs, so
+// that we can tell slow upstreams from slow clients.
+type timingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *timingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	dur := time.Since(start)
+	l := loggerFromContext(req.Context())
+	if !l.IsDisabled() {
+		l = l.Dur("upstream_duration_us", dur)
+		if err != nil {
+			l.WithError(err).Info("upstream failed")
+		} else {
+			l.Int("upstream_code", int64(resp.StatusCode)).Info("upstream responded")
+		}
+	}
+	return resp, err
+}
+
+// upstreamHealth tracks the result of the most recent health check; without
+// a health path configured, an upstream is always deemed healthy.
+type upstreamHealth struct {
+	healthy atomic.Bool
+}
+
+// monitor runs for the life of the process, logging only changes of state.
+func (up *upstreamHealth) monitor(client *http.Client, healthURL string, interval time.Duration, logger logging.Logger) {
+	logger = logger.WithField("health_url", logging.RedactURLString(healthURL))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		ok, err := checkUpstream(client, healthURL)
+		if was := up.healthy.Swap(ok); was != ok {
+			if ok {
+				logger.Info("upstream healthy")
+			} else if err != nil {
+				logger.WithError(err).Warning("upstream unhealthy")
+			} else {
+				logger.Warning("upstream unhealthy")
+			}
+		}
+		<-ticker.C
+	}
+}
+
+func checkUpstream(client *http.Client, healthURL string) (bool, error) {
+	resp, err := client.Get(healthURL)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return false, fmt.Errorf("health check status %d", resp.StatusCode)
+	}
+	return true, nil
+}
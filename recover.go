@@ -0,0 +1,142 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"github.com/felixge/httpsnoop"
+
+	"go.pennock.tech/dummyapp/internal/stats"
+)
+
+var panicOptions struct {
+	dumpDir string
+}
+
+func init() {
+	flag.StringVar(&panicOptions.dumpDir, "panic.dump-dir", "", "directory to write a dump file for each handler panic")
+}
+
+// newIncidentID returns an identifier for correlating what a client saw in a
+// 500 response with what we logged.  It's random rather than derived from
+// the request ID so that it can't be guessed from, nor leak, anything else.
+func newIncidentID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("t%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// RecoverWrapHandler recovers from any panic in the handler, sending a 500
+// with an incident ID if the response has not yet been started.  This is
+// applied to every page, whether or not logging is enabled, and is inside
+// LogWrapHandler so that the response is still logged.
+func RecoverWrapHandler(h http.Handler, name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var committed bool
+		ww := httpsnoop.Wrap(w, httpsnoop.Hooks{
+			WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+				return func(code int) {
+					next(code)
+					if code >= 200 {
+						committed = true
+					}
+				}
+			},
+			Write: func(next httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+				return func(b []byte) (int, error) {
+					committed = true
+					return next(b)
+				}
+			},
+			ReadFrom: func(next httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+				return func(src io.Reader) (int64, error) {
+					committed = true
+					return next(src)
+				}
+			},
+		})
+
+		defer func() {
+			x := recover()
+			if x == nil {
+				return
+			}
+			if x == http.ErrAbortHandler {
+				// This is the sanctioned way for a handler to abort a response;
+				// let net/http deal with it, quietly.
+				panic(x)
+			}
+			handlePanic(w, req, name, x, debug.Stack(), committed)
+		}()
+		h.ServeHTTP(ww, req)
+	}
+}
+
+func handlePanic(w http.ResponseWriter, req *http.Request, name string, x interface{}, stack []byte, committed bool) {
+	incident := newIncidentID()
+	stats.CountPanic(name)
+
+	logger := loggerFromContext(req.Context()).WithField("incident", incident)
+	logger.
+		WithField("panic", x).
+		WithField("stack", string(stack)).
+		Error("run-time panic")
+
+	if panicOptions.dumpDir != "" {
+		if err := writePanicDump(incident, req, name, x, stack); err != nil {
+			logger.WithError(err).Error("failed to write panic dump")
+		}
+	}
+
+	if committed {
+		// Too late for a clean error; the client gets a truncated response.
+		noteErrorClass(req, errorClassPanic)
+		return
+	}
+	writeProblem(w, req, errorClassPanic, problemDetails{
+		Status:   http.StatusInternalServerError,
+		Detail:   "internal error; please quote the incident ID when reporting this",
+		Incident: incident,
+	})
+}
+
+// writePanicDump writes one file per incident, named for the incident, for
+// later collection by whatever ships crash reports around.  We write to a
+// temporary name and rename, so that a collector never picks up a partial
+// file.
+func writePanicDump(incident string, req *http.Request, name string, x interface{}, stack []byte) error {
+	f, err := os.CreateTemp(panicOptions.dumpDir, ".panic-"+incident+"-*")
+	if err != nil {
+		return err
+	}
+	tmpName := f.Name()
+	fmt.Fprintf(f, "incident: %s\n", incident)
+	fmt.Fprintf(f, "time: %s\n", time.Now().UTC().Format(time.RFC3339Nano))
+	fmt.Fprintf(f, "page: %s\n", name)
+	fmt.Fprintf(f, "method: %s\n", req.Method)
+	fmt.Fprintf(f, "path: %s\n", req.URL.Path)
+	if id := requestIDFromContext(req.Context()); id != "" {
+		fmt.Fprintf(f, "request: %s\n", id)
+	}
+	fmt.Fprintf(f, "panic: %v\n\n", x)
+	f.Write(stack)
+	if err := f.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return os.Rename(tmpName, filepath.Join(panicOptions.dumpDir, "panic-"+incident+".txt"))
+}
@@ -0,0 +1,131 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"flag"
+	"hash/fnv"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Request IDs are ULID-style: 128 bits, rendered as 26 characters of
+// Crockford base32, so that they sort lexically by time.  The bits are:
+//
+//	48  milliseconds since the Unix epoch
+//	32  instance, from -request-id.instance or a hash of the hostname
+//	48  sequence, started at a random value and bumped for each request
+//
+// The instance keeps IDs unique across a cluster, and the random start of
+// the sequence keeps them unique across restarts of one instance.  Within
+// one process, IDs from the same millisecond sort in order of issue, as long
+// as the sequence doesn't wrap within that millisecond.
+//
+// From -trusted-proxies we accept an inbound X-Request-ID instead, so that
+// one ID can follow a request across services.
+
+const requestIDHeader = "X-Request-ID"
+
+// maxInboundRequestIDLen bounds what we'll accept and log from a proxy.
+const maxInboundRequestIDLen = 128
+
+var requestIDOptions struct {
+	instance string
+}
+
+func init() {
+	flag.StringVar(&requestIDOptions.instance, "request-id.instance", "", "instance name to make request IDs cluster-unique (default: hostname)")
+}
+
+var requestIDGen struct {
+	instance uint32
+	sequence atomic.Uint64
+}
+
+// setupRequestIDs should be called by the main go-routine after options have
+// been parsed, before serving.
+func setupRequestIDs() {
+	name := requestIDOptions.instance
+	if name == "" {
+		name, _ = os.Hostname()
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	requestIDGen.instance = h.Sum32()
+
+	var seed [8]byte
+	if _, err := rand.Read(seed[:]); err == nil {
+		requestIDGen.sequence.Store(binary.BigEndian.Uint64(seed[:]))
+	} else {
+		requestIDGen.sequence.Store(uint64(time.Now().UnixNano()))
+	}
+}
+
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newRequestID returns a new ID per the scheme described above.
+func newRequestID() string {
+	var b [16]byte
+	ms := uint64(time.Now().UnixMilli())
+	seq := requestIDGen.sequence.Add(1)
+	binary.BigEndian.PutUint64(b[0:8], ms<<16|uint64(requestIDGen.instance>>16))
+	binary.BigEndian.PutUint64(b[8:16], uint64(requestIDGen.instance&0xFFFF)<<48|seq&0xFFFFFFFFFFFF)
+	return encodeCrockford128(b)
+}
+
+// encodeCrockford128 encodes 128 bits as 26 base32 digits, most significant
+// first; the first digit only carries 3 bits.
+func encodeCrockford128(b [16]byte) string {
+	hi := binary.BigEndian.Uint64(b[0:8])
+	lo := binary.BigEndian.Uint64(b[8:16])
+	var out [26]byte
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockfordBase32[lo&0x1F]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// validInboundRequestID limits inbound IDs to a conservative character set,
+// since they end up in our logs and response headers.
+func validInboundRequestID(id string) bool {
+	if id == "" || len(id) > maxInboundRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '+', c == '/', c == '=':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDFor returns the ID to use for the request: an inbound one from a
+// trusted proxy if acceptable, else a new one.
+func requestIDFor(req *http.Request) string {
+	if id := req.Header.Get(requestIDHeader); id != "" && fromTrustedProxy(req) && validInboundRequestID(id) {
+		return id
+	}
+	return newRequestID()
+}
+
+// requestIDFromContext returns the ID of the request, or an empty string if
+// there is none, which happens when running without logging.
+func requestIDFromContext(ctx context.Context) string {
+	if state := requestStateFromContext(ctx); state != nil {
+		return state.id
+	}
+	return ""
+}
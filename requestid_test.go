@@ -0,0 +1,158 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeCrockford128(t *testing.T) {
+	var ones [16]byte
+	for i := range ones {
+		ones[i] = 0xFF
+	}
+	for _, tc := range []struct {
+		in   [16]byte
+		want string
+	}{
+		{[16]byte{}, "00000000000000000000000000"},
+		{[16]byte{15: 1}, "00000000000000000000000001"},
+		{[16]byte{15: 31}, "0000000000000000000000000Z"},
+		{[16]byte{15: 32}, "00000000000000000000000010"},
+		{[16]byte{0: 0x80}, "40000000000000000000000000"},
+		{ones, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ"},
+	} {
+		if got := encodeCrockford128(tc.in); got != tc.want {
+			t.Errorf("encodeCrockford128(%x) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+// decodeCrockford is the inverse of encodeCrockford128, for the tests.
+func decodeCrockford(t *testing.T, id string) (hi, lo uint64) {
+	t.Helper()
+	for i := 0; i < len(id); i++ {
+		d := strings.IndexByte(crockfordBase32, id[i])
+		if d < 0 {
+			t.Fatalf("request ID %q has non-Crockford character %q", id, id[i])
+		}
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(d)
+	}
+	return hi, lo
+}
+
+func TestNewRequestID(t *testing.T) {
+	saved := requestIDOptions.instance
+	defer func() {
+		requestIDOptions.instance = saved
+		setupRequestIDs()
+	}()
+
+	requestIDOptions.instance = "test-instance"
+	setupRequestIDs()
+	instance := requestIDGen.instance
+
+	before := time.Now().UnixMilli()
+	ids := make([]string, 1000)
+	for i := range ids {
+		ids[i] = newRequestID()
+	}
+	after := time.Now().UnixMilli()
+
+	seen := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		if len(id) != 26 {
+			t.Fatalf("request ID %q has length %d, want 26", id, len(id))
+		}
+		if seen[id] {
+			t.Fatalf("duplicate request ID %q", id)
+		}
+		seen[id] = true
+		if i > 0 && id <= ids[i-1] && id[:10] == ids[i-1][:10] {
+			t.Errorf("request IDs out of order within a millisecond: %q then %q", ids[i-1], id)
+		}
+
+		hi, lo := decodeCrockford(t, id)
+		if ms := int64(hi >> 16); ms < before || ms > after {
+			t.Errorf("request ID %q has timestamp %d, outside [%d, %d]", id, ms, before, after)
+		}
+		if got := uint32(hi&0xFFFF)<<16 | uint32(lo>>48); got != instance {
+			t.Errorf("request ID %q has instance %08x, want %08x", id, got, instance)
+		}
+		if !validInboundRequestID(id) {
+			t.Errorf("our own request ID %q fails inbound validation", id)
+		}
+	}
+
+	requestIDOptions.instance = "other-instance"
+	setupRequestIDs()
+	if requestIDGen.instance == instance {
+		t.Errorf("instances %q and %q hash the same", "test-instance", "other-instance")
+	}
+}
+
+func TestValidInboundRequestID(t *testing.T) {
+	for _, tc := range []struct {
+		id   string
+		want bool
+	}{
+		{"", false},
+		{"abc", true},
+		{"01M58Y46QB814W4B7ZVETY63T8", true},
+		{"f47ac10b-58cc-4372-a567-0e02b2c3d479", true},
+		{"svc:edge/1.2_x+y=", true},
+		{strings.Repeat("a", maxInboundRequestIDLen), true},
+		{strings.Repeat("a", maxInboundRequestIDLen+1), false},
+		{"has space", false},
+		{"new\nline", false},
+		{"quote\"d", false},
+		{"semi;colon", false},
+		{"café", false},
+		{"<script>", false},
+	} {
+		if got := validInboundRequestID(tc.id); got != tc.want {
+			t.Errorf("validInboundRequestID(%q) = %v, want %v", tc.id, got, tc.want)
+		}
+	}
+}
+
+func TestRequestIDFor(t *testing.T) {
+	saved := basePathOptions.trustedProxies
+	defer func() { basePathOptions.trustedProxies = saved }()
+	basePathOptions.trustedProxies = prefixListFlag{netip.MustParsePrefix("10.0.0.0/8")}
+	setupRequestIDs()
+
+	for _, tc := range []struct {
+		name    string
+		remote  string
+		inbound string
+		keep    bool
+	}{
+		{"trusted", "10.1.2.3:1234", "upstream-id-1", true},
+		{"untrusted", "192.0.2.1:1234", "upstream-id-1", false},
+		{"trusted but invalid", "10.1.2.3:1234", "bad id", false},
+		{"trusted without header", "10.1.2.3:1234", "", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tc.remote
+			if tc.inbound != "" {
+				req.Header.Set(requestIDHeader, tc.inbound)
+			}
+			got := requestIDFor(req)
+			if tc.keep && got != tc.inbound {
+				t.Errorf("requestIDFor = %q, want inbound %q", got, tc.inbound)
+			}
+			if !tc.keep && (got == tc.inbound || len(got) != 26) {
+				t.Errorf("requestIDFor = %q, want a new ID", got)
+			}
+		})
+	}
+}
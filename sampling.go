@@ -0,0 +1,62 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	"flag"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Request log sampling: with -request-log.sample-every N, LogWrapHandler logs
+// only every Nth successful request per page.  Errors, and requests slower
+// than -request-log.slow, are always logged.  The decision can only be made
+// once the request is done, so when sampling we don't log "received" at all
+// and instead put its fields on "responded".  The cost is that a request
+// which never completes goes unlogged.
+//
+// Each sampled line carries sample_rate, the number of requests it stands
+// for, so that downstream tools can re-weight counts: sum(sample_rate).
+
+var samplingOptions struct {
+	every uint64
+	slow  time.Duration
+}
+
+func init() {
+	flag.Uint64Var(&samplingOptions.every, "request-log.sample-every", 1, "log only every Nth successful request per page (1 to log all)")
+	flag.DurationVar(&samplingOptions.slow, "request-log.slow", time.Second, "when sampling, always log requests taking at least this long (0 to disable)")
+}
+
+// requestSampler makes the sampling decisions for one page.
+type requestSampler struct {
+	every uint64
+	slow  time.Duration
+	count atomic.Uint64
+}
+
+// newRequestSampler returns nil if we're not sampling.
+func newRequestSampler() *requestSampler {
+	if samplingOptions.every <= 1 {
+		return nil
+	}
+	return &requestSampler{every: samplingOptions.every, slow: samplingOptions.slow}
+}
+
+// sample says whether to log a request, and if so how many requests the log
+// line stands for.
+func (s *requestSampler) sample(code int, errorClass string, duration time.Duration) (log bool, rate uint64) {
+	if code >= http.StatusBadRequest || errorClass != "" {
+		return true, 1
+	}
+	if s.slow > 0 && duration >= s.slow {
+		return true, 1
+	}
+	if s.count.Add(1)%s.every == 0 {
+		return true, s.every
+	}
+	return false, 0
+}
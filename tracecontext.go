@@ -0,0 +1,154 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// W3C Trace Context, <https://www.w3.org/TR/trace-context/>: we continue any
+// trace given to us in `traceparent`, or start a new one, and each request
+// we serve is a span within it.  The IDs go into the per-request logger, so
+// that the log aggregator can stitch us into distributed traces, and are
+// propagated on our outbound requests.
+//
+// We don't record spans anywhere other than our logs, so we don't make
+// sampling decisions; we just propagate the inbound flags.
+
+const (
+	traceparentHeader = "traceparent"
+	tracestateHeader  = "tracestate"
+
+	// flagsSampled is used for traces which we start.
+	flagsSampled = "01"
+
+	// Per the spec, vendors must propagate at least 512 characters of
+	// tracestate and may discard it entirely if longer.
+	maxTracestateLen = 512
+)
+
+// traceContext is the tracing state of one request: the trace it belongs
+// to, the span which is our handling of it, and the remote span (if any)
+// which is our parent.
+type traceContext struct {
+	traceID  string // 32 lower-case hex digits
+	spanID   string // 16 lower-case hex digits
+	parentID string // 16 lower-case hex digits, or empty for a new trace
+	flags    string // 2 lower-case hex digits
+	state    string // raw tracestate, propagated unmodified
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		// Not great, but the failure of crypto/rand is not a reason to fail
+		// requests, and an all-zero ID is invalid per the spec so would just
+		// be replaced downstream.
+		return strings.Repeat("0", 2*n)
+	}
+	return hex.EncodeToString(b)
+}
+
+func isLowerHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func allZeroes(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
+
+// parseTraceparent returns the trace ID, parent span ID and flags from a
+// traceparent header, with ok false if it's not something we can continue.
+// Versions after 00 are parsed as 00, as the spec requires, provided that the
+// version 00 fields are intact.
+func parseTraceparent(value string) (traceID, parentID, flags string, ok bool) {
+	value = strings.TrimSpace(value)
+	if len(value) < 55 {
+		return "", "", "", false
+	}
+	version := value[0:2]
+	if !isLowerHex(version, 2) || version == "ff" {
+		return "", "", "", false
+	}
+	if version == "00" && len(value) != 55 {
+		return "", "", "", false
+	}
+	if len(value) > 55 && value[55] != '-' {
+		return "", "", "", false
+	}
+	if value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return "", "", "", false
+	}
+	traceID, parentID, flags = value[3:35], value[36:52], value[53:55]
+	if !isLowerHex(traceID, 32) || !isLowerHex(parentID, 16) || !isLowerHex(flags, 2) {
+		return "", "", "", false
+	}
+	if allZeroes(traceID) || allZeroes(parentID) {
+		return "", "", "", false
+	}
+	return traceID, parentID, flags, true
+}
+
+// traceContextFor continues the trace from the request's headers if it has
+// a valid traceparent, and otherwise starts a new trace.  Either way, the
+// span ID is new.
+func traceContextFor(req *http.Request) *traceContext {
+	tc := &traceContext{spanID: randomHex(8)}
+	if traceID, parentID, flags, ok := parseTraceparent(req.Header.Get(traceparentHeader)); ok {
+		tc.traceID, tc.parentID, tc.flags = traceID, parentID, flags
+		// tracestate is only meaningful alongside a valid traceparent; with
+		// multiple header lines, they're combined as a list.
+		if st := strings.Join(req.Header.Values(tracestateHeader), ","); len(st) <= maxTracestateLen {
+			tc.state = st
+		}
+	} else {
+		tc.traceID = randomHex(16)
+		tc.flags = flagsSampled
+	}
+	return tc
+}
+
+// traceparent renders the header for outbound requests made within our span,
+// so our span is the parent.
+func (tc *traceContext) traceparent() string {
+	return "00-" + tc.traceID + "-" + tc.spanID + "-" + tc.flags
+}
+
+// traceContextFromContext returns nil if the request isn't being traced,
+// which happens when running without logging.
+func traceContextFromContext(ctx context.Context) *traceContext {
+	if state := requestStateFromContext(ctx); state != nil {
+		return state.trace
+	}
+	return nil
+}
+
+// injectTraceContext sets the trace headers for an outbound request made on
+// behalf of the request whose context is given, replacing any already there.
+func injectTraceContext(ctx context.Context, h http.Header) {
+	tc := traceContextFromContext(ctx)
+	if tc == nil {
+		return
+	}
+	h.Set(traceparentHeader, tc.traceparent())
+	if tc.state != "" {
+		h.Set(tracestateHeader, tc.state)
+	} else {
+		h.Del(tracestateHeader)
+	}
+}
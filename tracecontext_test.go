@@ -0,0 +1,131 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	testTraceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testParentID = "00f067aa0ba902b7"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		value  string
+		ok     bool
+		flags  string
+		parent string
+	}{
+		{"spec example", "00-" + testTraceID + "-" + testParentID + "-01", true, "01", testParentID},
+		{"not sampled", "00-" + testTraceID + "-" + testParentID + "-00", true, "00", testParentID},
+		{"surrounding space", "  00-" + testTraceID + "-" + testParentID + "-01 ", true, "01", testParentID},
+		{"future version", "01-" + testTraceID + "-" + testParentID + "-01", true, "01", testParentID},
+		{"future version with more", "cc-" + testTraceID + "-" + testParentID + "-09-whatever", true, "09", testParentID},
+
+		{"empty", "", false, "", ""},
+		{"short", "00-" + testTraceID + "-" + testParentID + "-1", false, "", ""},
+		{"version ff", "ff-" + testTraceID + "-" + testParentID + "-01", false, "", ""},
+		{"version not hex", "0g-" + testTraceID + "-" + testParentID + "-01", false, "", ""},
+		{"version upper case", "0A-" + testTraceID + "-" + testParentID + "-01", false, "", ""},
+		{"version 00 too long", "00-" + testTraceID + "-" + testParentID + "-01-extra", false, "", ""},
+		{"future version bad separator", "01-" + testTraceID + "-" + testParentID + "-01x", false, "", ""},
+		{"bad separator", "00_" + testTraceID + "-" + testParentID + "-01", false, "", ""},
+		{"trace ID upper case", "00-" + strings.ToUpper(testTraceID) + "-" + testParentID + "-01", false, "", ""},
+		{"trace ID not hex", "00-" + strings.Repeat("z", 32) + "-" + testParentID + "-01", false, "", ""},
+		{"trace ID zero", "00-" + strings.Repeat("0", 32) + "-" + testParentID + "-01", false, "", ""},
+		{"parent ID zero", "00-" + testTraceID + "-" + strings.Repeat("0", 16) + "-01", false, "", ""},
+		{"parent ID not hex", "00-" + testTraceID + "-00f067aa0ba902bx-01", false, "", ""},
+		{"flags not hex", "00-" + testTraceID + "-" + testParentID + "-0x", false, "", ""},
+		{"trace ID short", "00-" + testTraceID[:31] + "-" + testParentID + "-01x", false, "", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			traceID, parentID, flags, ok := parseTraceparent(tc.value)
+			if ok != tc.ok {
+				t.Fatalf("parseTraceparent(%q) ok = %v, want %v", tc.value, ok, tc.ok)
+			}
+			if !ok {
+				if traceID != "" || parentID != "" || flags != "" {
+					t.Errorf("parseTraceparent(%q) returned values with !ok", tc.value)
+				}
+				return
+			}
+			if traceID != testTraceID || parentID != tc.parent || flags != tc.flags {
+				t.Errorf("parseTraceparent(%q) = %q, %q, %q", tc.value, traceID, parentID, flags)
+			}
+		})
+	}
+}
+
+func TestTraceContextFor(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(traceparentHeader, "00-"+testTraceID+"-"+testParentID+"-00")
+	req.Header.Add(tracestateHeader, "rojo=00f067aa0ba902b7")
+	req.Header.Add(tracestateHeader, "congo=t61rcWkgMzE")
+	tc := traceContextFor(req)
+	if tc.traceID != testTraceID || tc.parentID != testParentID || tc.flags != "00" {
+		t.Errorf("continued trace = %+v", tc)
+	}
+	if !isLowerHex(tc.spanID, 16) || tc.spanID == testParentID {
+		t.Errorf("span ID %q should be new", tc.spanID)
+	}
+	if want := "rojo=00f067aa0ba902b7,congo=t61rcWkgMzE"; tc.state != want {
+		t.Errorf("tracestate = %q, want %q", tc.state, want)
+	}
+	if want := "00-" + testTraceID + "-" + tc.spanID + "-00"; tc.traceparent() != want {
+		t.Errorf("traceparent() = %q, want %q", tc.traceparent(), want)
+	}
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(traceparentHeader, "ff-"+testTraceID+"-"+testParentID+"-01")
+	req.Header.Set(tracestateHeader, "rojo=00f067aa0ba902b7")
+	tc = traceContextFor(req)
+	if tc.traceID == testTraceID || !isLowerHex(tc.traceID, 32) || tc.parentID != "" || tc.flags != flagsSampled {
+		t.Errorf("new trace = %+v", tc)
+	}
+	if tc.state != "" {
+		t.Errorf("tracestate %q kept without a valid traceparent", tc.state)
+	}
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(traceparentHeader, "00-"+testTraceID+"-"+testParentID+"-01")
+	req.Header.Set(tracestateHeader, strings.Repeat("x", maxTracestateLen+1))
+	if tc = traceContextFor(req); tc.state != "" {
+		t.Errorf("over-long tracestate kept")
+	}
+}
+
+func TestInjectTraceContext(t *testing.T) {
+	tc := &traceContext{traceID: testTraceID, spanID: "b7ad6b7169203331", flags: "01"}
+	ctx := context.WithValue(context.Background(), dummyappRequestStateKey, &requestState{trace: tc})
+
+	h := http.Header{}
+	h.Set(tracestateHeader, "stale=1")
+	injectTraceContext(ctx, h)
+	if got, want := h.Get(traceparentHeader), "00-"+testTraceID+"-b7ad6b7169203331-01"; got != want {
+		t.Errorf("traceparent = %q, want %q", got, want)
+	}
+	if _, ok := h[http.CanonicalHeaderKey(tracestateHeader)]; ok {
+		t.Errorf("stale tracestate not removed")
+	}
+
+	tc.state = "rojo=1"
+	injectTraceContext(ctx, h)
+	if got := h.Get(tracestateHeader); got != "rojo=1" {
+		t.Errorf("tracestate = %q, want %q", got, "rojo=1")
+	}
+
+	h = http.Header{}
+	injectTraceContext(context.Background(), h)
+	if len(h) != 0 {
+		t.Errorf("headers set without a trace: %v", h)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.pennock.tech/dummyapp/internal/accesslog"
+	"go.pennock.tech/dummyapp/internal/logging"
+)
+
+// Virtual hosting: if any of the -vhost flags are given then we serve one
+// site per named host, each with its own subset of the pages, and requests
+// for any other host get the -vhost.default site or a 421.  Without them,
+// every host gets the default site with all pages.
+
+var vhostOptions struct {
+	pages       hostMapFlag
+	titles      hostMapFlag
+	poetryDirs  hostMapFlag
+	defaultHost string
+}
+
+func init() {
+	flag.Var(&vhostOptions.pages, "vhost", "`host=page,page,...` to serve only those pages for that host; use * for all pages (repeatable)")
+	flag.Var(&vhostOptions.titles, "vhost.title", "`host=title` for the index page of that host (repeatable)")
+	flag.Var(&vhostOptions.poetryDirs, "vhost.poetry", "`host=dir` poetry serving directory for that host (repeatable)")
+	flag.StringVar(&vhostOptions.defaultHost, "vhost.default", "", "virtual host to serve for unknown hosts; empty to reject them with 421")
+}
+
+// stringMapFlag is a flag.Value accumulating repeated `key=value` flags;
+// later values for the same key replace earlier ones.
+type stringMapFlag map[string]string
+
+func (m *stringMapFlag) String() string {
+	if m == nil || *m == nil {
+		return ""
+	}
+	keys := make([]string, 0, len(*m))
+	for k := range *m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i := range keys {
+		keys[i] = keys[i] + "=" + (*m)[keys[i]]
+	}
+	return strings.Join(keys, " ")
+}
+
+func (m *stringMapFlag) Set(value string) error {
+	k, v, ok := strings.Cut(value, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("%q not of form key=value", value)
+	}
+	if *m == nil {
+		*m = make(map[string]string)
+	}
+	(*m)[k] = v
+	return nil
+}
+
+// hostMapFlag is a stringMapFlag keyed by canonicalHost, so that the flags
+// for one host agree however it's written, where giving the same host twice
+// is an error rather than a silent replacement.
+type hostMapFlag map[string]string
+
+func (m *hostMapFlag) String() string {
+	return (*stringMapFlag)(m).String()
+}
+
+func (m *hostMapFlag) Set(value string) error {
+	k, v, ok := strings.Cut(value, "=")
+	host := canonicalHost(k)
+	if !ok || host == "" {
+		return fmt.Errorf("%q not of form host=value", value)
+	}
+	if _, dup := (*m)[host]; dup {
+		return fmt.Errorf("host %q given more than once", host)
+	}
+	if *m == nil {
+		*m = make(map[string]string)
+	}
+	(*m)[host] = v
+	return nil
+}
+
+// canonicalHost lower-cases and strips any port and trailing dot, for
+// comparing the Host header against our configuration.
+func canonicalHost(hostport string) string {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+func vhostsConfigured() bool {
+	return len(vhostOptions.pages) > 0 || len(vhostOptions.titles) > 0 || len(vhostOptions.poetryDirs) > 0
+}
+
+// setupSites returns the handler for all pages of all sites.
+func setupSites(logger logging.Logger) http.Handler {
+	if !vhostsConfigured() {
+		return newDefaultSite().handler(logger)
+	}
+
+	hostSet := make(map[string]struct{})
+	for _, m := range []hostMapFlag{vhostOptions.pages, vhostOptions.titles, vhostOptions.poetryDirs} {
+		for h := range m {
+			hostSet[h] = struct{}{}
+		}
+	}
+
+	d := &vhostDispatcher{sites: make(map[string]http.Handler, len(hostSet))}
+	for host := range hostSet {
+		sl := logger.WithField("site", host)
+		s := newVirtualSite(host, sl)
+		d.sites[s.name] = s.handler(sl)
+		sl.WithField("pages", len(s.pages)).Info("virtual host configured")
+	}
+
+	misdirected := RecoverWrapHandler(http.HandlerFunc(sendMisdirected), "misdirected")
+	if !logger.IsDisabled() || accesslog.Enabled() {
+		misdirected = LogWrapHandler(misdirected, logger, "misdirected")
+	}
+	d.fallback = misdirected
+	if vhostOptions.defaultHost != "" {
+		if h, ok := d.sites[canonicalHost(vhostOptions.defaultHost)]; ok {
+			d.fallback = h
+		} else {
+			logger.WithField("site", vhostOptions.defaultHost).Warning("default virtual host not configured, unknown hosts will get 421")
+		}
+	}
+	return d
+}
+
+// newVirtualSite selects the pages for one host from all the registered
+// pages, substituting the host's own poetry if it has some.  The host is
+// already canonical, as the keys of the flags are.
+func newVirtualSite(host string, logger logging.Logger) *site {
+	s := &site{
+		name:  host,
+		title: vhostOptions.titles[host],
+		pages: make(map[string]dummyAppFirstLevelPage),
+	}
+	if s.title == "" {
+		s.title = defaultSiteTitle
+	}
+
+	spec, listed := vhostOptions.pages[host]
+	if !listed || spec == "*" {
+		for k, page := range firstLevelPages {
+			s.pages[k] = page
+		}
+	} else {
+		// favicon.ico is plumbing rather than a page anyone would list
+		s.pages["favicon.ico"] = firstLevelPages["favicon.ico"]
+		for _, want := range strings.Split(spec, ",") {
+			want = strings.Trim(strings.TrimSpace(want), "/")
+			if want == "" {
+				continue
+			}
+			found := false
+			for k, page := range firstLevelPages {
+				if strings.TrimRight(k, "/") == want {
+					s.pages[k] = page
+					found = true
+				}
+			}
+			if !found {
+				logger.WithField("page", want).Warning("virtual host lists unknown page")
+			}
+		}
+	}
+
+	if dir, ok := vhostOptions.poetryDirs[host]; ok {
+		pl := logger.WithField("directory", dir)
+		if err := checkDir(dir); err != nil {
+			pl.WithError(err).Warning("skipping poetry setup")
+		} else {
+			for _, k := range []string{"poetry/", "poetry", "poem/"} {
+				delete(s.pages, k)
+			}
+			for _, page := range poetryPages(dir) {
+				s.pages[page.name] = page
+			}
+			pl.Info("serving poetry")
+		}
+	}
+	return s
+}
+
+type vhostDispatcher struct {
+	sites    map[string]http.Handler
+	fallback http.Handler
+}
+
+func (d *vhostDispatcher) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if h, ok := d.sites[canonicalHost(req.Host)]; ok {
+		h.ServeHTTP(w, req)
+		return
+	}
+	d.fallback.ServeHTTP(w, req)
+}
+
+func sendMisdirected(w http.ResponseWriter, req *http.Request) {
+	sendProblem(w, req, http.StatusMisdirectedRequest, errorClassMisdirected, "this server does not serve that host")
+}
@@ -0,0 +1,32 @@
+// Copyright © 2026 Pennock Tech, LLC.
+// All rights reserved, except as granted under license.
+// Licensed per file LICENSE.txt
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHostMapFlag(t *testing.T) {
+	var m hostMapFlag
+	for _, value := range []string{"Example.COM=aws,ip", "other.example.:8080=*", "x.example=a=b"} {
+		if err := m.Set(value); err != nil {
+			t.Fatalf("Set(%q): %v", value, err)
+		}
+	}
+	want := hostMapFlag{"example.com": "aws,ip", "other.example": "*", "x.example": "a=b"}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("flags = %v, want %v", m, want)
+	}
+
+	for _, value := range []string{"example.com=ip", "EXAMPLE.com.=ip", "example.com:443=ip", "noequals", "=value", ":80=x"} {
+		if err := m.Set(value); err == nil {
+			t.Errorf("Set(%q) accepted", value)
+		}
+	}
+	if m["example.com"] != "aws,ip" {
+		t.Errorf("rejected duplicate replaced the value: %q", m["example.com"])
+	}
+}